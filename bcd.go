@@ -0,0 +1,74 @@
+package modbus
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxBCDRegisters is the most registers whose digits can fit in a uint64
+const maxBCDRegisters = 5
+
+// ReadBCD reads quantity holding registers of packed binary-coded decimal,
+// four digits per register with the most significant register first
+func (c *Client) ReadBCD(slaveID byte, address, quantity uint16) (uint64, error) {
+	if quantity == 0 || quantity > maxBCDRegisters {
+		return 0, fmt.Errorf("invalid BCD quantity: %d (must be 1-%d)", quantity, maxBCDRegisters)
+	}
+
+	registers, err := c.ReadHoldingRegisters(slaveID, address, quantity)
+	if err != nil {
+		return 0, err
+	}
+
+	return decodeBCD(registers)
+}
+
+// WriteBCD writes value as packed binary-coded decimal across quantity
+// holding registers, zero-padded on the left
+func (c *Client) WriteBCD(slaveID byte, address, quantity uint16, value uint64) error {
+	if quantity == 0 || quantity > maxBCDRegisters {
+		return fmt.Errorf("invalid BCD quantity: %d (must be 1-%d)", quantity, maxBCDRegisters)
+	}
+
+	registers, err := encodeBCD(value, int(quantity))
+	if err != nil {
+		return err
+	}
+
+	return c.WriteMultipleRegisters(slaveID, address, registers)
+}
+
+// decodeBCD converts packed BCD registers to an integer, rejecting nibbles
+// above 9 and values that overflow a uint64
+func decodeBCD(registers []uint16) (uint64, error) {
+	var value uint64
+	for i, reg := range registers {
+		for shift := 12; shift >= 0; shift -= 4 {
+			digit := uint64(reg>>uint(shift)) & 0x0F
+			if digit > 9 {
+				return 0, fmt.Errorf("invalid BCD digit 0x%X in register %d (0x%04X)", digit, i, reg)
+			}
+			if value > (math.MaxUint64-digit)/10 {
+				return 0, fmt.Errorf("BCD value overflows uint64")
+			}
+			value = value*10 + digit
+		}
+	}
+	return value, nil
+}
+
+// encodeBCD converts value to count packed BCD registers
+func encodeBCD(value uint64, count int) ([]uint16, error) {
+	registers := make([]uint16, count)
+	remaining := value
+	for i := count - 1; i >= 0; i-- {
+		for shift := 0; shift < 16; shift += 4 {
+			registers[i] |= uint16(remaining%10) << uint(shift)
+			remaining /= 10
+		}
+	}
+	if remaining != 0 {
+		return nil, fmt.Errorf("value %d does not fit in %d BCD digits", value, 4*count)
+	}
+	return registers, nil
+}
@@ -0,0 +1,90 @@
+package modbus
+
+import (
+	"math"
+	"testing"
+)
+
+// TestBCDConversion tests packed BCD encoding and decoding
+func TestBCDConversion(t *testing.T) {
+	tests := []struct {
+		name      string
+		registers []uint16
+		value     uint64
+	}{
+		{"zero", []uint16{0x0000}, 0},
+		{"single register", []uint16{0x1234}, 1234},
+		{"leading zeros", []uint16{0x0000, 0x0042}, 42},
+		{"two registers", []uint16{0x0012, 0x3456}, 123456},
+		{"max digits", []uint16{0x9999, 0x9999, 0x9999}, 999999999999},
+		{"max uint64", []uint16{0x1844, 0x6744, 0x0737, 0x0955, 0x1615}, math.MaxUint64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := decodeBCD(tt.registers)
+			if err != nil {
+				t.Fatalf("decodeBCD failed: %v", err)
+			}
+			if value != tt.value {
+				t.Errorf("Expected %d, got %d", tt.value, value)
+			}
+
+			registers, err := encodeBCD(tt.value, len(tt.registers))
+			if err != nil {
+				t.Fatalf("encodeBCD failed: %v", err)
+			}
+			for i := range registers {
+				if registers[i] != tt.registers[i] {
+					t.Errorf("Register %d: expected 0x%04X, got 0x%04X", i, tt.registers[i], registers[i])
+				}
+			}
+		})
+	}
+}
+
+// TestBCDErrors tests rejection of invalid digits, overflow and oversized values
+func TestBCDErrors(t *testing.T) {
+	if _, err := decodeBCD([]uint16{0x12A4}); err == nil {
+		t.Error("Expected error for nibble above 9")
+	}
+	if _, err := decodeBCD([]uint16{0x9999, 0x9999, 0x9999, 0x9999, 0x9999}); err == nil {
+		t.Error("Expected error for value overflowing uint64")
+	}
+	if _, err := encodeBCD(12345, 1); err == nil {
+		t.Error("Expected error for value with too many digits")
+	}
+}
+
+// TestReadWriteBCD tests BCD round trips through a device
+func TestReadWriteBCD(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	if err := client.WriteBCD(1, 30, 3, 87654321); err != nil {
+		t.Fatalf("WriteBCD failed: %v", err)
+	}
+
+	server.mutex.Lock()
+	raw := []uint16{server.registers[30], server.registers[31], server.registers[32]}
+	server.registers[40] = 0x00F1
+	server.mutex.Unlock()
+	if raw[0] != 0x0000 || raw[1] != 0x8765 || raw[2] != 0x4321 {
+		t.Errorf("Expected registers [0x0000 0x8765 0x4321], got %04X", raw)
+	}
+
+	value, err := client.ReadBCD(1, 30, 3)
+	if err != nil {
+		t.Fatalf("ReadBCD failed: %v", err)
+	}
+	if value != 87654321 {
+		t.Errorf("Expected 87654321, got %d", value)
+	}
+
+	if _, err := client.ReadBCD(1, 40, 1); err == nil {
+		t.Error("Expected error reading invalid BCD register")
+	}
+	if _, err := client.ReadBCD(1, 30, 6); err == nil {
+		t.Error("Expected error for quantity above 5")
+	}
+}
@@ -0,0 +1,85 @@
+package modbus
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Range is a span of consecutive holding registers
+type Range struct {
+	Address  uint16 // First register
+	Quantity uint16 // Number of registers
+}
+
+// ReadBlocks reads several holding register ranges, one read per range (or
+// more for ranges over 125 registers), and merges the values into a map
+// keyed by address. Overlapping and adjacent ranges are read together.
+func (c *Client) ReadBlocks(slaveID byte, ranges []Range) (map[uint16]uint16, error) {
+	return c.ReadBlocksWithGap(slaveID, ranges, 0)
+}
+
+// ReadBlocksWithGap is ReadBlocks that also merges ranges separated by at
+// most maxGap unused registers into a single read, as long as the merged read
+// still fits in one request. Only use it when reading the gap registers is
+// harmless; their values are not included in the result.
+func (c *Client) ReadBlocksWithGap(slaveID byte, ranges []Range, maxGap uint16) (map[uint16]uint16, error) {
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no ranges to read")
+	}
+	for _, r := range ranges {
+		if r.Quantity == 0 {
+			return nil, fmt.Errorf("invalid quantity: 0 at address %d", r.Address)
+		}
+		if err := checkAddressRange(r.Address, int(r.Quantity)); err != nil {
+			return nil, err
+		}
+	}
+
+	values := make(map[uint16]uint16)
+	for _, span := range planBlocks(ranges, maxGap) {
+		registers, err := c.ReadRegistersChunked(slaveID, span.Address, span.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range ranges {
+			for i := 0; i < int(r.Quantity); i++ {
+				address := int(r.Address) + i
+				offset := address - int(span.Address)
+				if offset >= 0 && offset < len(registers) {
+					values[uint16(address)] = registers[offset]
+				}
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// planBlocks sorts ranges and merges those that overlap, touch, or are
+// separated by at most maxGap registers when the result fits in one read
+func planBlocks(ranges []Range, maxGap uint16) []Range {
+	sorted := append([]Range(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Address < sorted[j].Address })
+
+	var spans []Range
+	for _, r := range sorted {
+		if len(spans) > 0 {
+			last := &spans[len(spans)-1]
+			lastEnd := int(last.Address) + int(last.Quantity)
+			end := int(r.Address) + int(r.Quantity)
+			if end < lastEnd {
+				end = lastEnd
+			}
+
+			overlaps := int(r.Address) <= lastEnd
+			fits := int(r.Address)-lastEnd <= int(maxGap) && end-int(last.Address) <= maxReadRegisters
+			if overlaps || fits {
+				last.Quantity = uint16(end - int(last.Address))
+				continue
+			}
+		}
+		spans = append(spans, r)
+	}
+
+	return spans
+}
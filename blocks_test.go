@@ -0,0 +1,92 @@
+package modbus
+
+import "testing"
+
+// TestPlanBlocks tests merging of register ranges into reads
+func TestPlanBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		ranges   []Range
+		maxGap   uint16
+		expected []Range
+	}{
+		{"separate", []Range{{100, 2}, {10, 5}}, 0, []Range{{10, 5}, {100, 2}}},
+		{"adjacent", []Range{{10, 5}, {15, 5}}, 0, []Range{{10, 10}}},
+		{"overlapping", []Range{{10, 10}, {12, 3}}, 0, []Range{{10, 10}}},
+		{"small gap merged", []Range{{10, 5}, {18, 2}}, 3, []Range{{10, 10}}},
+		{"gap too large", []Range{{10, 5}, {19, 2}}, 3, []Range{{10, 5}, {19, 2}}},
+		{"merge would exceed a read", []Range{{0, 100}, {102, 30}}, 5, []Range{{0, 100}, {102, 30}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := planBlocks(tt.ranges, tt.maxGap)
+			if len(spans) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, spans)
+			}
+			for i := range spans {
+				if spans[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, spans)
+					break
+				}
+			}
+		})
+	}
+}
+
+// TestReadBlocks tests reading ranges around registers the device faults on
+func TestReadBlocks(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	for address := uint16(0); address < 300; address++ {
+		server.registers[address] = address + 1000
+	}
+
+	// Registers 20-199 fault when read
+	var reads int
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] != FuncCodeReadHoldingRegisters {
+			return nil
+		}
+		reads++
+		start := uint16(pdu[1])<<8 | uint16(pdu[2])
+		end := start + (uint16(pdu[3])<<8 | uint16(pdu[4]))
+		if start < 200 && end > 20 {
+			return []byte{pdu[0] | 0x80, ExceptionIllegalDataAddress}
+		}
+		return nil
+	}
+
+	ranges := []Range{{Address: 0, Quantity: 4}, {Address: 16, Quantity: 4}, {Address: 200, Quantity: 3}}
+
+	values, err := client.ReadBlocks(1, ranges)
+	if err != nil {
+		t.Fatalf("ReadBlocks failed: %v", err)
+	}
+	if len(values) != 11 || values[0] != 1000 || values[19] != 1019 || values[202] != 1202 {
+		t.Errorf("Unexpected values: %v", values)
+	}
+	if _, ok := values[10]; ok {
+		t.Error("Expected gap register 10 not to be reported")
+	}
+
+	server.mutex.Lock()
+	separateReads := reads
+	reads = 0
+	server.mutex.Unlock()
+
+	values, err = client.ReadBlocksWithGap(1, ranges, 12)
+	if err != nil {
+		t.Fatalf("ReadBlocksWithGap failed: %v", err)
+	}
+	if len(values) != 11 || values[16] != 1016 {
+		t.Errorf("Unexpected values: %v", values)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if separateReads != 3 || reads != 2 {
+		t.Errorf("Expected 3 separate and 2 merged reads, got %d and %d", separateReads, reads)
+	}
+}
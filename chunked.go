@@ -0,0 +1,170 @@
+package modbus
+
+import "fmt"
+
+// Largest register counts allowed in a single read (0x03) and write (0x10),
+// and the largest coil count in a single read (0x01)
+const (
+	maxReadRegisters  = 125
+	maxWriteRegisters = 123
+	maxReadCoils      = 2000
+)
+
+// readRegisterLimit returns the most registers one read may request without
+// the response PDU exceeding MaxPDUSize
+func (c *Client) readRegisterLimit() int {
+	return chunkLimit(maxReadRegisters, (c.maxPDU-2)/2)
+}
+
+// writeRegisterLimit returns the most registers one Write Multiple Registers
+// request may carry within MaxPDUSize
+func (c *Client) writeRegisterLimit() int {
+	return chunkLimit(maxWriteRegisters, (c.maxPDU-6)/2)
+}
+
+// readBitLimit returns the most coils or discrete inputs one read may request
+// without the response PDU exceeding MaxPDUSize
+func (c *Client) readBitLimit() int {
+	return chunkLimit(maxReadCoils, (c.maxPDU-2)*8)
+}
+
+// alignLimit rounds a chunk limit down to whole values of width registers,
+// so no multi-register value is split across two requests
+func alignLimit(limit, width int) int {
+	if width > 1 {
+		limit -= limit % width
+		if limit < width {
+			limit = width
+		}
+	}
+	return limit
+}
+
+// chunkLimit caps a protocol limit by what fits in the PDU, never going
+// below one so a tiny MaxPDUSize fails in sendRequest rather than looping
+func chunkLimit(protocolLimit, fits int) int {
+	if fits < protocolLimit {
+		protocolLimit = fits
+	}
+	if protocolLimit < 1 {
+		return 1
+	}
+	return protocolLimit
+}
+
+// PartialWriteError reports a chunked write that failed part way through.
+// Written registers starting at the original address were stored before the
+// failure, so the write can be resumed from address+Written.
+type PartialWriteError struct {
+	Written int   // Registers successfully written
+	Err     error // Error from the failing chunk
+}
+
+func (e *PartialWriteError) Error() string {
+	return fmt.Sprintf("chunked write failed after %d registers: %v", e.Written, e.Err)
+}
+
+// Unwrap returns the error from the failing chunk
+func (e *PartialWriteError) Unwrap() error {
+	return e.Err
+}
+
+// WriteRegistersChunked writes any number of consecutive holding registers,
+// splitting the write into Write Multiple Registers requests of at most 123
+// registers each, or fewer when MaxPDUSize requires. If a chunk fails the
+// error is a *PartialWriteError.
+func (c *Client) WriteRegistersChunked(slaveID byte, address uint16, values []uint16) error {
+	return c.writeRegistersChunked(slaveID, address, values, 1)
+}
+
+// writeRegistersChunked is WriteRegistersChunked for values of width
+// registers each, which are never split across requests
+func (c *Client) writeRegistersChunked(slaveID byte, address uint16, values []uint16, width int) error {
+	if len(values) == 0 {
+		return fmt.Errorf("no values to write")
+	}
+	if err := checkAddressRange(address, len(values)); err != nil {
+		return err
+	}
+
+	for written := 0; written < len(values); {
+		end := written + alignLimit(c.writeRegisterLimit(), width)
+		if end > len(values) {
+			end = len(values)
+		}
+		if err := c.WriteMultipleRegisters(slaveID, address+uint16(written), values[written:end]); err != nil {
+			return &PartialWriteError{Written: written, Err: err}
+		}
+		written = end
+	}
+
+	return nil
+}
+
+// ReadRegistersChunked reads any number of consecutive holding registers,
+// issuing as many Read Holding Registers requests of at most 125 registers
+// (fewer when MaxPDUSize requires) as needed and returning the values in
+// address order
+func (c *Client) ReadRegistersChunked(slaveID byte, address, quantity uint16) ([]uint16, error) {
+	return c.readRegistersChunked(slaveID, FuncCodeReadHoldingRegisters, address, quantity, 1)
+}
+
+// readRegistersChunked is ReadRegistersChunked for either register table and
+// for values of width registers each, which are never split across requests
+func (c *Client) readRegistersChunked(slaveID, funcCode byte, address, quantity uint16, width int) ([]uint16, error) {
+	if quantity == 0 {
+		return nil, fmt.Errorf("invalid quantity: 0")
+	}
+	if err := checkAddressRange(address, int(quantity)); err != nil {
+		return nil, err
+	}
+
+	values := make([]uint16, 0, quantity)
+	for len(values) < int(quantity) {
+		count := int(quantity) - len(values)
+		if limit := alignLimit(c.readRegisterLimit(), width); count > limit {
+			count = limit
+		}
+		chunk, err := c.readRegisters(slaveID, funcCode, address+uint16(len(values)), uint16(count), requestOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read registers at %d: %w", int(address)+len(values), err)
+		}
+		values = append(values, chunk...)
+	}
+
+	return values, nil
+}
+
+// ReadCoilsChunked reads any number of consecutive coils, issuing as many
+// Read Coils requests of at most 2000 coils (fewer when MaxPDUSize requires)
+// as needed and returning the values in address order
+func (c *Client) ReadCoilsChunked(slaveID byte, address, quantity uint16) ([]bool, error) {
+	return c.readBitsChunked(slaveID, FuncCodeReadCoils, address, quantity)
+}
+
+// readBitsChunked is ReadCoilsChunked for either bit table
+func (c *Client) readBitsChunked(slaveID, funcCode byte, address, quantity uint16) ([]bool, error) {
+	if quantity == 0 {
+		return nil, fmt.Errorf("invalid quantity: 0")
+	}
+	if err := checkAddressRange(address, int(quantity)); err != nil {
+		return nil, err
+	}
+
+	values := make([]bool, 0, quantity)
+	for len(values) < int(quantity) {
+		count := int(quantity) - len(values)
+		if limit := c.readBitLimit(); count > limit {
+			count = limit
+		}
+		response, err := c.readBitsResponse(slaveID, funcCode, address+uint16(len(values)), uint16(count), requestOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bits at %d: %w", int(address)+len(values), err)
+		}
+		chunk := make([]bool, count)
+		decodeCoils(response, chunk)
+		values = append(values, chunk...)
+	}
+
+	return values, nil
+}
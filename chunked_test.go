@@ -0,0 +1,264 @@
+package modbus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestWriteRegistersChunked tests splitting large writes into 0x10 requests
+func TestWriteRegistersChunked(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	var chunks []int
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] == FuncCodeWriteMultipleRegisters {
+			chunks = append(chunks, int(pdu[3])<<8|int(pdu[4]))
+		}
+		return nil
+	}
+
+	values := make([]uint16, 500)
+	for i := range values {
+		values[i] = uint16(i * 3)
+	}
+	if err := client.WriteRegistersChunked(1, 1000, values); err != nil {
+		t.Fatalf("WriteRegistersChunked failed: %v", err)
+	}
+
+	expected := []int{123, 123, 123, 123, 8}
+	server.mutex.Lock()
+	if len(chunks) != len(expected) {
+		t.Errorf("Expected chunks %v, got %v", expected, chunks)
+	} else {
+		for i := range chunks {
+			if chunks[i] != expected[i] {
+				t.Errorf("Expected chunks %v, got %v", expected, chunks)
+				break
+			}
+		}
+	}
+	for i := range values {
+		if server.registers[1000+uint16(i)] != values[i] {
+			t.Errorf("Register %d: expected %d, got %d", 1000+i, values[i], server.registers[1000+uint16(i)])
+			break
+		}
+	}
+	server.mutex.Unlock()
+
+	if err := client.WriteRegistersChunked(1, 65500, values); err == nil {
+		t.Error("Expected address range overflow error")
+	}
+	if err := client.WriteRegistersChunked(1, 0, nil); err == nil {
+		t.Error("Expected error for empty write")
+	}
+}
+
+// TestWriteRegistersChunkedPartial tests reporting progress when a chunk fails
+func TestWriteRegistersChunkedPartial(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	// Reject the third chunk; it starts at 2*123 registers past address 0
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] == FuncCodeWriteMultipleRegisters && int(pdu[1])<<8|int(pdu[2]) == 246 {
+			return []byte{pdu[0] | 0x80, ExceptionSlaveDeviceFailure}
+		}
+		return nil
+	}
+
+	err := client.WriteRegistersChunked(1, 0, make([]uint16, 300))
+	var partial *PartialWriteError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected *PartialWriteError, got %v", err)
+	}
+	if partial.Written != 246 {
+		t.Errorf("Expected 246 registers written, got %d", partial.Written)
+	}
+	var modbusErr *ModbusError
+	if !errors.As(err, &modbusErr) || modbusErr.ExceptionCode != ExceptionSlaveDeviceFailure {
+		t.Errorf("Expected wrapped slave device failure, got %v", partial.Err)
+	}
+}
+
+// TestReadRegistersChunked tests reading a block larger than one request allows
+func TestReadRegistersChunked(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	for i := uint16(0); i < 300; i++ {
+		server.registers[100+i] = i + 1
+	}
+
+	values, err := client.ReadRegistersChunked(1, 100, 300)
+	if err != nil {
+		t.Fatalf("ReadRegistersChunked failed: %v", err)
+	}
+	if len(values) != 300 {
+		t.Fatalf("Expected 300 values, got %d", len(values))
+	}
+	for i, value := range values {
+		if value != uint16(i+1) {
+			t.Errorf("Value %d: expected %d, got %d", i, i+1, value)
+			break
+		}
+	}
+
+	// Exactly one chunk
+	values, err = client.ReadRegistersChunked(1, 100, 125)
+	if err != nil || len(values) != 125 {
+		t.Errorf("Expected 125 values, got %d (err %v)", len(values), err)
+	}
+
+	if _, err := client.ReadRegistersChunked(1, 65500, 100); err == nil {
+		t.Error("Expected address range overflow error")
+	}
+}
+
+// TestReadCoilsChunked tests reading more coils than one request allows
+func TestReadCoilsChunked(t *testing.T) {
+	device := NewMemoryTransport()
+	client := NewClientWithTransport(device, ClientConfig{})
+
+	for i := uint16(0); i < 5000; i++ {
+		device.SetCoil(10+i, i%3 == 0)
+	}
+
+	values, err := client.ReadCoilsChunked(1, 10, 5000)
+	if err != nil {
+		t.Fatalf("ReadCoilsChunked failed: %v", err)
+	}
+	if len(values) != 5000 {
+		t.Fatalf("Expected 5000 values, got %d", len(values))
+	}
+	for i, value := range values {
+		if value != (i%3 == 0) {
+			t.Errorf("Coil %d: expected %v, got %v", i, i%3 == 0, value)
+			break
+		}
+	}
+
+	// Exactly one chunk
+	values, err = client.ReadCoilsChunked(1, 10, 2000)
+	if err != nil || len(values) != 2000 {
+		t.Errorf("Expected 2000 values, got %d (err %v)", len(values), err)
+	}
+
+	if _, err := client.ReadCoilsChunked(1, 65000, 1000); err == nil {
+		t.Error("Expected address range overflow error")
+	}
+}
+
+// TestMaxPDUSize tests chunk sizing and request rejection for small PDU limits
+func TestMaxPDUSize(t *testing.T) {
+	device := NewMemoryTransport()
+	var largest, requests int
+	client := NewClientWithTransport(device, ClientConfig{
+		MaxPDUSize: 64,
+		OnTransaction: func(request, response []byte, err error) {
+			requests++
+			for _, frame := range [][]byte{request, response} {
+				if len(frame)-7 > largest {
+					largest = len(frame) - 7
+				}
+			}
+		},
+	})
+
+	values := make([]uint16, 100)
+	for i := range values {
+		values[i] = uint16(i)
+	}
+
+	tests := []struct {
+		name     string
+		call     func() error
+		requests int
+	}{
+		{"write registers", func() error { return client.WriteRegistersChunked(1, 0, values) }, 4},           // 29 per request
+		{"read registers", func() error { _, err := client.ReadRegistersChunked(1, 0, 100); return err }, 4}, // 31 per request
+		{"read coils", func() error { _, err := client.ReadCoilsChunked(1, 0, 1000); return err }, 3},        // 496 per request
+	}
+
+	for _, tt := range tests {
+		requests, largest = 0, 0
+		if err := tt.call(); err != nil {
+			t.Fatalf("%s failed: %v", tt.name, err)
+		}
+		if requests != tt.requests {
+			t.Errorf("%s: expected %d requests, got %d", tt.name, tt.requests, requests)
+		}
+		if largest > 64 {
+			t.Errorf("%s: expected PDUs of at most 64 bytes, got %d", tt.name, largest)
+		}
+	}
+
+	requests = 0
+	if _, err := client.SendRawPDU(1, make([]byte, 65)); err == nil {
+		t.Error("Expected error for a PDU over the limit")
+	}
+	if requests != 0 {
+		t.Errorf("Expected the oversized PDU not to be sent, got %d requests", requests)
+	}
+
+	standard := NewClientWithTransport(device, ClientConfig{})
+	if _, err := standard.SendRawPDU(1, make([]byte, MaxPDUSize+1)); err == nil {
+		t.Error("Expected error for a PDU over the spec limit")
+	}
+}
+
+// TestFloat32Chunks tests that bulk float reads and writes never split a
+// float across requests
+func TestFloat32Chunks(t *testing.T) {
+	server := NewMockServer()
+	var reads, writes []int
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		quantity := int(pdu[3])<<8 | int(pdu[4])
+		switch pdu[0] {
+		case FuncCodeReadHoldingRegisters:
+			reads = append(reads, quantity)
+		case FuncCodeWriteMultipleRegisters:
+			writes = append(writes, quantity)
+			if len(writes) == 3 {
+				return []byte{pdu[0] | 0x80, ExceptionSlaveDeviceFailure}
+			}
+		}
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	if _, err := client.ReadFloat32s(1, 0, 100, ByteOrderBig); err != nil {
+		t.Fatalf("ReadFloat32s failed: %v", err)
+	}
+	if len(reads) != 2 || reads[0] != 124 || reads[1] != 76 {
+		t.Errorf("Expected reads of [124 76] registers, got %v", reads)
+	}
+
+	err := client.WriteFloat32s(1, 0, make([]float32, 200), ByteOrderBig)
+	var partial *PartialWriteError
+	if !errors.As(err, &partial) || partial.Written != 244 {
+		t.Fatalf("Expected partial write after 244 registers, got %v", err)
+	}
+	if writes[0] != 122 || writes[1] != 122 {
+		t.Errorf("Expected writes of 122 registers, got %v", writes)
+	}
+
+	reads = nil
+	if _, err := ReadAs[float32](client, 1, TableHoldingRegisters, 0, 100, ByteOrderBig); err != nil {
+		t.Fatalf("ReadAs failed: %v", err)
+	}
+	if len(reads) != 2 || reads[0] != 124 {
+		t.Errorf("Expected ReadAs reads of [124 76] registers, got %v", reads)
+	}
+
+	if _, err := client.ReadFloat32s(1, 0, 32768, ByteOrderBig); err == nil || !strings.Contains(err.Error(), "invalid float count") {
+		t.Errorf("Expected invalid float count, got %v", err)
+	}
+	if _, err := ReadAs[float32](client, 1, TableHoldingRegisters, 0, 32768, ByteOrderBig); err == nil || !strings.Contains(err.Error(), "invalid count") {
+		t.Errorf("Expected invalid count, got %v", err)
+	}
+	if _, err := ReadAs[bool](client, 1, TableCoils, 0, 65536, ByteOrderBig); err == nil || !strings.Contains(err.Error(), "invalid count") {
+		t.Errorf("Expected invalid count, got %v", err)
+	}
+}
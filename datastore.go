@@ -0,0 +1,127 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// DataStore is a Handler backed by in-memory coil and register tables, for
+// emulating a device with Server. It supports the same function codes as
+// MemoryTransport, and OnWrite hooks let tests observe register writes made
+// by a master. A DataStore is safe for concurrent use.
+type DataStore struct {
+	mutex     sync.Mutex
+	coils     map[uint16]bool
+	registers map[uint16]uint16
+	onWrite   map[uint16][]func(old, new uint16)
+}
+
+// NewDataStore creates a store with all coils off and all registers zero
+func NewDataStore() *DataStore {
+	return &DataStore{
+		coils:     make(map[uint16]bool),
+		registers: make(map[uint16]uint16),
+		onWrite:   make(map[uint16][]func(old, new uint16)),
+	}
+}
+
+// SetCoil sets the coil at address
+func (d *DataStore) SetCoil(address uint16, value bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.coils[address] = value
+}
+
+// Coil returns the coil at address
+func (d *DataStore) Coil(address uint16) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.coils[address]
+}
+
+// SetRegister sets the register at address. It doesn't trigger OnWrite
+// callbacks, which only report writes by a master.
+func (d *DataStore) SetRegister(address, value uint16) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.registers[address] = value
+}
+
+// Register returns the register at address
+func (d *DataStore) Register(address uint16) uint16 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.registers[address]
+}
+
+// OnWrite registers a callback run after every master write to the holding
+// register at address, with its value before and after the write. Callbacks
+// run on the serving goroutine once the store is unlocked, so they may use
+// the store but delay the response while they run.
+func (d *DataStore) OnWrite(address uint16, callback func(old, new uint16)) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.onWrite[address] = append(d.onWrite[address], callback)
+}
+
+// ServeModbus implements Handler
+func (d *DataStore) ServeModbus(unitID byte, pdu []byte) ([]byte, error) {
+	type change struct {
+		address       uint16
+		before, after uint16
+	}
+
+	d.mutex.Lock()
+	written := writtenRegisters(pdu)
+	old := make([]uint16, len(written))
+	for i, address := range written {
+		old[i] = d.registers[address]
+	}
+
+	response := handleMemoryDevice(d.coils, d.registers, pdu)
+
+	var changes []change
+	if len(response) > 0 && response[0]&0x80 == 0 {
+		for i, address := range written {
+			changes = append(changes, change{address, old[i], d.registers[address]})
+		}
+	}
+	callbacks := make([][]func(old, new uint16), len(changes))
+	for i, c := range changes {
+		callbacks[i] = d.onWrite[c.address]
+	}
+	d.mutex.Unlock()
+
+	for i, c := range changes {
+		for _, callback := range callbacks[i] {
+			callback(c.before, c.after)
+		}
+	}
+
+	return response, nil
+}
+
+// writtenRegisters returns the holding register addresses a request PDU
+// writes, or nil when it writes none
+func writtenRegisters(pdu []byte) []uint16 {
+	if len(pdu) < 5 {
+		return nil
+	}
+	address := binary.BigEndian.Uint16(pdu[1:3])
+
+	switch pdu[0] {
+	case FuncCodeWriteSingleRegister, FuncCodeMaskWriteRegister:
+		return []uint16{address}
+	case FuncCodeWriteMultipleRegisters:
+		quantity := binary.BigEndian.Uint16(pdu[3:5])
+		if len(pdu) < 6+2*int(quantity) {
+			return nil // Rejected by the device as malformed
+		}
+		addresses := make([]uint16, quantity)
+		for i := range addresses {
+			addresses[i] = address + uint16(i)
+		}
+		return addresses
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package modbus
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestDataStore tests serving a data store and observing master writes
+func TestDataStore(t *testing.T) {
+	store := NewDataStore()
+	store.SetRegister(10, 5)
+	store.SetCoil(2, true)
+
+	type write struct{ old, new uint16 }
+	var mutex sync.Mutex
+	writes := make(map[uint16][]write)
+	for _, address := range []uint16{10, 11, 20} {
+		address := address
+		store.OnWrite(address, func(old, new uint16) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			writes[address] = append(writes[address], write{old, new})
+		})
+	}
+
+	server := NewServer()
+	server.Handle(1, store)
+	client := startTestServer(t, server)
+
+	registers, err := client.ReadHoldingRegisters(1, 10, 1)
+	if err != nil || registers[0] != 5 {
+		t.Errorf("Expected [5], got %v (err %v)", registers, err)
+	}
+	coils, err := client.ReadCoils(1, 2, 1)
+	if err != nil || !coils[0] {
+		t.Errorf("Expected [true], got %v (err %v)", coils, err)
+	}
+
+	if err := client.WriteSingleRegister(1, 10, 42); err != nil {
+		t.Fatalf("WriteSingleRegister failed: %v", err)
+	}
+	if err := client.WriteMultipleRegisters(1, 10, []uint16{43, 44}); err != nil {
+		t.Fatalf("WriteMultipleRegisters failed: %v", err)
+	}
+	if err := client.MaskWriteRegister(1, 20, 0x00FF, 0x1200); err != nil {
+		t.Fatalf("MaskWriteRegister failed: %v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected := map[uint16][]write{
+		10: {{5, 42}, {42, 43}},
+		11: {{0, 44}},
+		20: {{0, 0x1200}},
+	}
+	for address, want := range expected {
+		got := writes[address]
+		if len(got) != len(want) {
+			t.Errorf("Register %d: expected writes %v, got %v", address, want, got)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("Register %d write %d: expected %v, got %v", address, i, want[i], got[i])
+			}
+		}
+	}
+	if store.Register(11) != 44 {
+		t.Errorf("Expected register 11 = 44, got %d", store.Register(11))
+	}
+}
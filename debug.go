@@ -0,0 +1,54 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// FormatFrame renders an MBAP framed request or response as annotated hex,
+// for logs and bug reports. For example, a Read Holding Registers request
+// for 10 registers at address 0 on unit 1 is rendered as
+//
+//	txn=0x0001 proto=0x0000 len=6 unit=1 | fc=0x03 data=[00 00 00 0A]
+//
+// Exception responses are marked with "exception" after the function code.
+// Frames too short for an MBAP header are dumped as plain hex, and a length
+// field that disagrees with the frame size is flagged.
+func FormatFrame(frame []byte) string {
+	if len(frame) < 7 {
+		return fmt.Sprintf("short frame: [% X]", frame)
+	}
+
+	transactionID := binary.BigEndian.Uint16(frame[0:2])
+	protocolID := binary.BigEndian.Uint16(frame[2:4])
+	length := int(binary.BigEndian.Uint16(frame[4:6]))
+
+	out := fmt.Sprintf("txn=0x%04X proto=0x%04X len=%d unit=%d", transactionID, protocolID, length, frame[6])
+	if length != len(frame)-6 {
+		out += fmt.Sprintf(" (length mismatch: %d bytes follow)", len(frame)-6)
+	}
+
+	pdu := frame[7:]
+	if len(pdu) == 0 {
+		return out + " | empty PDU"
+	}
+	out += fmt.Sprintf(" | fc=0x%02X", pdu[0])
+	if pdu[0] >= 0x80 {
+		out += " exception"
+	}
+	return out + fmt.Sprintf(" data=[% X]", pdu[1:])
+}
+
+// LogTransactions returns an OnTransaction hook that writes every exchange
+// to logf using FormatFrame
+func LogTransactions(logf func(format string, args ...interface{})) func(request, response []byte, err error) {
+	return func(request, response []byte, err error) {
+		logf("modbus request:  %s", FormatFrame(request))
+		if response != nil {
+			logf("modbus response: %s", FormatFrame(response))
+		}
+		if err != nil {
+			logf("modbus error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package modbus
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFormatFrame tests the annotated frame dump
+func TestFormatFrame(t *testing.T) {
+	tests := []struct {
+		name     string
+		frame    []byte
+		expected string
+	}{
+		{
+			"read request",
+			[]byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x06, 0x01, 0x03, 0x00, 0x00, 0x00, 0x0A},
+			"txn=0x0001 proto=0x0000 len=6 unit=1 | fc=0x03 data=[00 00 00 0A]",
+		},
+		{
+			"exception response",
+			[]byte{0x12, 0x34, 0x00, 0x00, 0x00, 0x03, 0x11, 0x83, 0x02},
+			"txn=0x1234 proto=0x0000 len=3 unit=17 | fc=0x83 exception data=[02]",
+		},
+		{
+			"length mismatch",
+			[]byte{0x00, 0x02, 0x00, 0x00, 0x00, 0x09, 0x01, 0x03, 0x02},
+			"txn=0x0002 proto=0x0000 len=9 unit=1 (length mismatch: 3 bytes follow) | fc=0x03 data=[02]",
+		},
+		{
+			"header only",
+			[]byte{0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x01},
+			"txn=0x0003 proto=0x0000 len=1 unit=1 | empty PDU",
+		},
+		{
+			"short frame",
+			[]byte{0x00, 0x01, 0x00},
+			"short frame: [00 01 00]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatFrame(tt.frame); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestLogTransactions tests the logging transaction hook
+func TestLogTransactions(t *testing.T) {
+	server := NewMockServer()
+
+	var lines []string
+	logf := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout:       time.Second,
+		OnTransaction: LogTransactions(logf),
+	})
+
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("Expected request and response lines, got %v", lines)
+	}
+	if !strings.Contains(lines[0], "fc=0x03 data=[00 00 00 01]") {
+		t.Errorf("Unexpected request line: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "fc=0x03 data=[02 00 00]") {
+		t.Errorf("Unexpected response line: %s", lines[1])
+	}
+}
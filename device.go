@@ -0,0 +1,90 @@
+package modbus
+
+// Device binds a client to one slave ID so calls for a single device don't
+// repeat it. It is a lightweight value; create as many as needed.
+type Device struct {
+	client  *Client
+	slaveID byte
+}
+
+// Device returns a handle for the device at slaveID on this client
+func (c *Client) Device(slaveID byte) *Device {
+	return &Device{client: c, slaveID: slaveID}
+}
+
+// DefaultDevice returns a handle for the configured DefaultUnitID, so
+// callers talking to a single device never pass a unit ID
+func (c *Client) DefaultDevice() *Device {
+	return c.Device(c.defaultUnit)
+}
+
+// Client returns the underlying client
+func (d *Device) Client() *Client {
+	return d.client
+}
+
+// SlaveID returns the slave ID the device is bound to
+func (d *Device) SlaveID() byte {
+	return d.slaveID
+}
+
+// ReadCoils reads coils (function code 0x01)
+func (d *Device) ReadCoils(address, quantity uint16) ([]bool, error) {
+	return d.client.ReadCoils(d.slaveID, address, quantity)
+}
+
+// ReadDiscreteInputs reads discrete inputs (function code 0x02)
+func (d *Device) ReadDiscreteInputs(address, quantity uint16) ([]bool, error) {
+	return d.client.ReadDiscreteInputs(d.slaveID, address, quantity)
+}
+
+// ReadHoldingRegisters reads holding registers (function code 0x03)
+func (d *Device) ReadHoldingRegisters(address, quantity uint16) ([]uint16, error) {
+	return d.client.ReadHoldingRegisters(d.slaveID, address, quantity)
+}
+
+// ReadInputRegisters reads input registers (function code 0x04)
+func (d *Device) ReadInputRegisters(address, quantity uint16) ([]uint16, error) {
+	return d.client.ReadInputRegisters(d.slaveID, address, quantity)
+}
+
+// WriteSingleCoil writes a single coil (function code 0x05)
+func (d *Device) WriteSingleCoil(address uint16, value bool) error {
+	return d.client.WriteSingleCoil(d.slaveID, address, value)
+}
+
+// WriteSingleRegister writes a single register (function code 0x06)
+func (d *Device) WriteSingleRegister(address, value uint16) error {
+	return d.client.WriteSingleRegister(d.slaveID, address, value)
+}
+
+// WriteMultipleCoils writes multiple coils (function code 0x0F)
+func (d *Device) WriteMultipleCoils(address uint16, values []bool) error {
+	return d.client.WriteMultipleCoils(d.slaveID, address, values)
+}
+
+// WriteMultipleRegisters writes multiple registers (function code 0x10)
+func (d *Device) WriteMultipleRegisters(address uint16, values []uint16) error {
+	return d.client.WriteMultipleRegisters(d.slaveID, address, values)
+}
+
+// MaskWriteRegister modifies a holding register using AND and OR masks
+// (function code 0x16)
+func (d *Device) MaskWriteRegister(address, andMask, orMask uint16) error {
+	return d.client.MaskWriteRegister(d.slaveID, address, andMask, orMask)
+}
+
+// ReadFloat32 reads a 32-bit float from two consecutive registers
+func (d *Device) ReadFloat32(address uint16, byteOrder ByteOrder) (float32, error) {
+	return d.client.ReadFloat32(d.slaveID, address, byteOrder)
+}
+
+// WriteFloat32 writes a 32-bit float to two consecutive registers
+func (d *Device) WriteFloat32(address uint16, value float32, byteOrder ByteOrder) error {
+	return d.client.WriteFloat32(d.slaveID, address, value, byteOrder)
+}
+
+// Ping checks that the device answers
+func (d *Device) Ping() error {
+	return d.client.Ping(d.slaveID)
+}
@@ -0,0 +1,70 @@
+package modbus
+
+import "testing"
+
+// TestDevice tests that a device handle addresses its bound slave ID
+func TestDevice(t *testing.T) {
+	server := NewMockServer()
+	var units []byte
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		units = append(units, unitID)
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	device := client.Device(7)
+	if device.SlaveID() != 7 || device.Client() != client {
+		t.Fatalf("Expected device bound to slave 7 on client, got %d", device.SlaveID())
+	}
+
+	if err := device.WriteSingleRegister(3, 300); err != nil {
+		t.Fatalf("WriteSingleRegister failed: %v", err)
+	}
+	if err := device.WriteMultipleCoils(0, []bool{true, true}); err != nil {
+		t.Fatalf("WriteMultipleCoils failed: %v", err)
+	}
+
+	registers, err := device.ReadHoldingRegisters(3, 1)
+	if err != nil || registers[0] != 300 {
+		t.Errorf("Expected [300], got %v (err %v)", registers, err)
+	}
+	coils, err := device.ReadCoils(0, 2)
+	if err != nil || !coils[0] || !coils[1] {
+		t.Errorf("Expected [true true], got %v (err %v)", coils, err)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if len(units) != 4 {
+		t.Fatalf("Expected 4 requests, got %d", len(units))
+	}
+	for i, unitID := range units {
+		if unitID != 7 {
+			t.Errorf("Request %d: expected unit ID 7, got %d", i, unitID)
+		}
+	}
+}
+
+// TestDefaultDevice tests addressing the configured default unit ID
+func TestDefaultDevice(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   ClientConfig
+		expected byte
+	}{
+		{"unset", ClientConfig{}, TCPUnitID},
+		{"configured", ClientConfig{DefaultUnitID: 17}, 17},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := NewClientWithTransport(NewMemoryTransport(), tt.config).DefaultDevice()
+			if device.SlaveID() != tt.expected {
+				t.Errorf("Expected unit ID %d, got %d", tt.expected, device.SlaveID())
+			}
+			if _, err := device.ReadHoldingRegisters(0, 1); err != nil {
+				t.Errorf("Read on default device failed: %v", err)
+			}
+		})
+	}
+}
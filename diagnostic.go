@@ -0,0 +1,84 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Diagnostics (function code 0x08) sub-function codes
+const (
+	diagRestartCommunications = 0x0001
+	diagForceListenOnlyMode   = 0x0004
+)
+
+// ForceListenOnlyMode puts the device into listen-only mode (Diagnostics
+// sub-function 0x0004): it keeps monitoring the bus but stops answering
+// requests until RestartCommunications is sent. The device never responds to
+// this request, so it is sent without waiting for a reply.
+func (c *Client) ForceListenOnlyMode(slaveID byte) error {
+	_, err := c.sendRequestWith(slaveID, diagnosticPDU(diagForceListenOnlyMode, 0), requestOptions{noResponse: true})
+	if err != nil {
+		return err
+	}
+
+	c.statsMutex.Lock()
+	if c.listenOnly == nil {
+		c.listenOnly = make(map[byte]bool)
+	}
+	c.listenOnly[slaveID] = true
+	c.statsMutex.Unlock()
+
+	return nil
+}
+
+// RestartCommunications restarts the device's serial line port and takes it
+// out of listen-only mode (Diagnostics sub-function 0x0001). With clearLog
+// set, the device also clears its communications event log. A device in
+// listen-only mode restarts without answering, so if this client put slaveID
+// into listen-only mode the request is sent without waiting for a reply.
+func (c *Client) RestartCommunications(slaveID byte, clearLog bool) error {
+	var data uint16
+	if clearLog {
+		data = 0xFF00
+	}
+	pdu := diagnosticPDU(diagRestartCommunications, data)
+
+	c.statsMutex.Lock()
+	listenOnly := c.listenOnly[slaveID]
+	c.statsMutex.Unlock()
+
+	response, err := c.sendRequestWith(slaveID, pdu, requestOptions{noResponse: listenOnly})
+	if err != nil {
+		return err
+	}
+
+	c.statsMutex.Lock()
+	delete(c.listenOnly, slaveID)
+	c.statsMutex.Unlock()
+
+	if listenOnly {
+		return nil
+	}
+
+	// Verify echo response
+	if len(response) != len(pdu) || response[0] != FuncCodeDiagnostics {
+		return fmt.Errorf("invalid response")
+	}
+	if binary.BigEndian.Uint16(response[1:3]) != diagRestartCommunications {
+		return fmt.Errorf("response sub-function mismatch")
+	}
+	if binary.BigEndian.Uint16(response[3:5]) != data {
+		return fmt.Errorf("response data mismatch")
+	}
+
+	return nil
+}
+
+// diagnosticPDU builds a Diagnostics PDU with a single data word
+func diagnosticPDU(subFunction, data uint16) []byte {
+	pdu := make([]byte, 5)
+	pdu[0] = FuncCodeDiagnostics
+	binary.BigEndian.PutUint16(pdu[1:3], subFunction)
+	binary.BigEndian.PutUint16(pdu[3:5], data)
+	return pdu
+}
@@ -0,0 +1,76 @@
+package modbus
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestListenOnlyMode tests forcing listen-only mode and restarting
+func TestListenOnlyMode(t *testing.T) {
+	var mutex sync.Mutex
+	var requests [][]byte
+	listenOnly := false
+
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests = append(requests, append([]byte(nil), pdu...))
+
+		switch {
+		case bytes.Equal(pdu[:3], []byte{FuncCodeDiagnostics, 0x00, 0x04}):
+			listenOnly = true
+			return []byte{}
+		case bytes.Equal(pdu[:3], []byte{FuncCodeDiagnostics, 0x00, 0x01}):
+			if listenOnly {
+				listenOnly = false
+				return []byte{}
+			}
+			return pdu
+		case listenOnly:
+			return []byte{}
+		}
+		return nil
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: 200 * time.Millisecond})
+
+	if err := client.ForceListenOnlyMode(1); err != nil {
+		t.Fatalf("ForceListenOnlyMode failed: %v", err)
+	}
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err == nil {
+		t.Error("Expected timeout in listen-only mode, got nil")
+	}
+
+	// The device restarts silently out of listen-only mode
+	if err := client.RestartCommunications(1, false); err != nil {
+		t.Fatalf("RestartCommunications failed: %v", err)
+	}
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Expected read after restart to succeed, got %v", err)
+	}
+
+	// Once out of listen-only mode a restart is echoed
+	if err := client.RestartCommunications(1, true); err != nil {
+		t.Fatalf("RestartCommunications failed: %v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected := [][]byte{
+		{FuncCodeDiagnostics, 0x00, 0x04, 0x00, 0x00},
+		{FuncCodeReadHoldingRegisters, 0x00, 0x00, 0x00, 0x01},
+		{FuncCodeDiagnostics, 0x00, 0x01, 0x00, 0x00},
+		{FuncCodeReadHoldingRegisters, 0x00, 0x00, 0x00, 0x01},
+		{FuncCodeDiagnostics, 0x00, 0x01, 0xFF, 0x00},
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("Expected %d requests, got %d", len(expected), len(requests))
+	}
+	for i, request := range requests {
+		if !bytes.Equal(request, expected[i]) {
+			t.Errorf("Request %d: expected % X, got % X", i, expected[i], request)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
 	"log"
 	"time"
@@ -17,6 +18,9 @@ func main() {
 
 	// Example 3: Connection pool for high-performance scenarios
 	poolExample()
+
+	// Example 4: Secured connection over TLS
+	tlsExample()
 }
 
 // basicExample demonstrates basic Modbus operations
@@ -218,6 +222,33 @@ func poolExample() {
 	fmt.Printf("Concurrent operations completed in %v\n", duration)
 }
 
+// tlsExample demonstrates connecting with the Modbus/TCP Security profile
+func tlsExample() {
+	fmt.Println("\n=== TLS Connection ===")
+
+	config := modbus.ClientConfig{
+		Address: "192.168.1.100:802", // Secured devices listen on port 802
+		Timeout: 5 * time.Second,
+		TLSConfig: &tls.Config{
+			ServerName: "plc.example.com", // Must match the device certificate
+		},
+	}
+
+	client, err := modbus.NewClient(config)
+	if err != nil {
+		log.Printf("Failed to create TLS client: %v", err)
+		return
+	}
+	defer client.Close()
+
+	registers, err := client.ReadHoldingRegisters(1, 0, 5)
+	if err != nil {
+		log.Printf("Failed to read holding registers: %v", err)
+	} else {
+		fmt.Printf("Holding registers 0-4: %v\n", registers)
+	}
+}
+
 // advancedExample demonstrates advanced features like float handling
 func advancedExample() {
 	fmt.Println("\n=== Advanced Features ===")
@@ -0,0 +1,171 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// fileRecordReferenceType is the only reference type defined by the spec
+// for file record sub-requests
+const fileRecordReferenceType = 0x06
+
+// FileRecordRequest describes one record group to read with ReadFileRecord
+type FileRecordRequest struct {
+	FileNumber   uint16 // File number (1-65535)
+	RecordNumber uint16 // Starting record within the file (0-9999)
+	RecordLength uint16 // Number of 16-bit registers to read
+}
+
+// FileRecordResponse holds the data returned for one FileRecordRequest
+type FileRecordResponse struct {
+	ReferenceType byte     // Reference type echoed by the device (always 6)
+	Data          []uint16 // Record contents
+}
+
+// FileRecord is one record group written by WriteFileRecord
+type FileRecord struct {
+	FileNumber   uint16   // File number (1-65535)
+	RecordNumber uint16   // Starting record within the file (0-9999)
+	Data         []uint16 // Registers to write starting at RecordNumber
+}
+
+// validateFileRecord checks the addressing fields shared by file record reads and writes
+func validateFileRecord(fileNumber, recordNumber uint16) error {
+	if fileNumber == 0 {
+		return fmt.Errorf("invalid file number: 0 (must be 1-65535)")
+	}
+	if recordNumber > 9999 {
+		return fmt.Errorf("invalid record number: %d (must be 0-9999)", recordNumber)
+	}
+	return nil
+}
+
+// ReadFileRecord reads one or more record groups (function code 0x14) in a
+// single transaction. Responses are returned in request order.
+func (c *Client) ReadFileRecord(slaveID byte, requests []FileRecordRequest) ([]FileRecordResponse, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("no file record requests")
+	}
+
+	byteCount := 7 * len(requests)
+	if byteCount > 0xF5 {
+		return nil, fmt.Errorf("too many file record requests: %d (request byte count %d exceeds 245)",
+			len(requests), byteCount)
+	}
+
+	// The whole response must fit in one PDU: function code, byte count,
+	// then a length and reference type byte per sub-response plus its data
+	responseSize := 2
+	for i, req := range requests {
+		if err := validateFileRecord(req.FileNumber, req.RecordNumber); err != nil {
+			return nil, fmt.Errorf("request %d: %w", i, err)
+		}
+		if req.RecordLength == 0 {
+			return nil, fmt.Errorf("request %d: invalid record length: 0", i)
+		}
+		responseSize += 2 + 2*int(req.RecordLength)
+	}
+	if responseSize > 253 {
+		return nil, fmt.Errorf("requested records too large: response would be %d bytes (max 253)", responseSize)
+	}
+
+	// Build PDU
+	pdu := make([]byte, 2+byteCount)
+	pdu[0] = FuncCodeReadFileRecord
+	pdu[1] = byte(byteCount)
+	for i, req := range requests {
+		sub := pdu[2+7*i:]
+		sub[0] = fileRecordReferenceType
+		binary.BigEndian.PutUint16(sub[1:3], req.FileNumber)
+		binary.BigEndian.PutUint16(sub[3:5], req.RecordNumber)
+		binary.BigEndian.PutUint16(sub[5:7], req.RecordLength)
+	}
+
+	response, err := c.sendRequest(slaveID, pdu)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response) < 2 {
+		return nil, fmt.Errorf("invalid response length")
+	}
+	if len(response) != 2+int(response[1]) {
+		return nil, fmt.Errorf("response length mismatch")
+	}
+
+	// Parse the concatenated sub-responses
+	results := make([]FileRecordResponse, len(requests))
+	data := response[2:]
+	for i, req := range requests {
+		if len(data) < 2 {
+			return nil, fmt.Errorf("response truncated at sub-response %d", i)
+		}
+		length := int(data[0])
+		if length != 1+2*int(req.RecordLength) || len(data) < 1+length {
+			return nil, fmt.Errorf("sub-response %d length mismatch", i)
+		}
+		if data[1] != fileRecordReferenceType {
+			return nil, fmt.Errorf("sub-response %d has invalid reference type 0x%02X", i, data[1])
+		}
+
+		values := make([]uint16, req.RecordLength)
+		for j := range values {
+			values[j] = binary.BigEndian.Uint16(data[2+j*2 : 4+j*2])
+		}
+		results[i] = FileRecordResponse{ReferenceType: data[1], Data: values}
+		data = data[1+length:]
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("unexpected %d trailing bytes in response", len(data))
+	}
+
+	return results, nil
+}
+
+// WriteFileRecord writes one or more record groups (function code 0x15) in a
+// single transaction. The device echoes the request, which is verified.
+func (c *Client) WriteFileRecord(slaveID byte, records []FileRecord) error {
+	if len(records) == 0 {
+		return fmt.Errorf("no file records")
+	}
+
+	byteCount := 0
+	for i, record := range records {
+		if err := validateFileRecord(record.FileNumber, record.RecordNumber); err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+		if len(record.Data) == 0 {
+			return fmt.Errorf("record %d: no data", i)
+		}
+		byteCount += 7 + 2*len(record.Data)
+	}
+	if byteCount > 0xFB {
+		return fmt.Errorf("file records too large: request byte count %d exceeds 251", byteCount)
+	}
+
+	// Build PDU
+	pdu := make([]byte, 2, 2+byteCount)
+	pdu[0] = FuncCodeWriteFileRecord
+	pdu[1] = byte(byteCount)
+	for _, record := range records {
+		pdu = append(pdu, fileRecordReferenceType)
+		pdu = binary.BigEndian.AppendUint16(pdu, record.FileNumber)
+		pdu = binary.BigEndian.AppendUint16(pdu, record.RecordNumber)
+		pdu = binary.BigEndian.AppendUint16(pdu, uint16(len(record.Data)))
+		for _, value := range record.Data {
+			pdu = binary.BigEndian.AppendUint16(pdu, value)
+		}
+	}
+
+	response, err := c.sendRequest(slaveID, pdu)
+	if err != nil || slaveID == BroadcastSlaveID {
+		return err
+	}
+
+	// Verify echo response
+	if string(response) != string(pdu) {
+		return fmt.Errorf("response does not echo the request")
+	}
+
+	return nil
+}
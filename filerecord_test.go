@@ -0,0 +1,151 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// fileRecordHandler serves file record reads from files, keyed by file number
+func fileRecordHandler(files map[uint16][]uint16) func(unitID byte, pdu []byte) []byte {
+	return func(unitID byte, pdu []byte) []byte {
+		if pdu[0] != FuncCodeReadFileRecord {
+			return nil
+		}
+
+		response := []byte{pdu[0], 0}
+		for sub := pdu[2:]; len(sub) >= 7; sub = sub[7:] {
+			file := files[binary.BigEndian.Uint16(sub[1:3])]
+			record := binary.BigEndian.Uint16(sub[3:5])
+			length := binary.BigEndian.Uint16(sub[5:7])
+
+			response = append(response, byte(1+2*length), fileRecordReferenceType)
+			for i := uint16(0); i < length; i++ {
+				response = binary.BigEndian.AppendUint16(response, file[record+i])
+			}
+		}
+		response[1] = byte(len(response) - 2)
+		return response
+	}
+}
+
+// TestReadFileRecord tests packing sub-requests and parsing sub-responses
+func TestReadFileRecord(t *testing.T) {
+	server := NewMockServer()
+	server.handler = fileRecordHandler(map[uint16][]uint16{
+		4: {0x0D, 0xFE, 0x20, 0x33, 0x44},
+		3: {0x06, 0xAF, 0x04, 0xBE, 0x100D},
+	})
+	client := newTestClient(t, server)
+
+	responses, err := client.ReadFileRecord(1, []FileRecordRequest{
+		{FileNumber: 4, RecordNumber: 1, RecordLength: 2},
+		{FileNumber: 3, RecordNumber: 0, RecordLength: 3},
+	})
+	if err != nil {
+		t.Fatalf("ReadFileRecord failed: %v", err)
+	}
+
+	expected := [][]uint16{{0xFE, 0x20}, {0x06, 0xAF, 0x04}}
+	if len(responses) != len(expected) {
+		t.Fatalf("Expected %d responses, got %d", len(expected), len(responses))
+	}
+	for i, want := range expected {
+		if responses[i].ReferenceType != fileRecordReferenceType {
+			t.Errorf("Response %d: expected reference type 6, got %d", i, responses[i].ReferenceType)
+		}
+		if len(responses[i].Data) != len(want) {
+			t.Errorf("Response %d: expected %d registers, got %d", i, len(want), len(responses[i].Data))
+			continue
+		}
+		for j := range want {
+			if responses[i].Data[j] != want[j] {
+				t.Errorf("Response %d register %d: expected 0x%04X, got 0x%04X",
+					i, j, want[j], responses[i].Data[j])
+			}
+		}
+	}
+}
+
+// TestReadFileRecordValidation tests rejection of invalid requests and responses
+func TestReadFileRecordValidation(t *testing.T) {
+	client := &Client{}
+	invalid := [][]FileRecordRequest{
+		nil,
+		{{FileNumber: 0, RecordNumber: 0, RecordLength: 1}},
+		{{FileNumber: 1, RecordNumber: 10000, RecordLength: 1}},
+		{{FileNumber: 1, RecordNumber: 0, RecordLength: 0}},
+		{{FileNumber: 1, RecordNumber: 0, RecordLength: 126}},
+	}
+	for i, requests := range invalid {
+		if _, err := client.ReadFileRecord(1, requests); err == nil {
+			t.Errorf("Case %d: expected validation error", i)
+		}
+	}
+
+	// A device returning fewer registers than requested must be rejected
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		return []byte{pdu[0], 4, 3, fileRecordReferenceType, 0x00, 0x01}
+	}
+	client = newTestClient(t, server)
+	if _, err := client.ReadFileRecord(1, []FileRecordRequest{{FileNumber: 1, RecordLength: 2}}); err == nil {
+		t.Error("Expected error for short sub-response")
+	}
+}
+
+// TestWriteFileRecord tests building the write PDU and verifying the echo
+func TestWriteFileRecord(t *testing.T) {
+	files := map[uint16][]uint16{4: make([]uint16, 10), 5: make([]uint16, 10)}
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] != FuncCodeWriteFileRecord {
+			return fileRecordHandler(files)(unitID, pdu)
+		}
+		for sub := pdu[2:]; len(sub) >= 7; {
+			file := files[binary.BigEndian.Uint16(sub[1:3])]
+			record := binary.BigEndian.Uint16(sub[3:5])
+			length := binary.BigEndian.Uint16(sub[5:7])
+			for i := uint16(0); i < length; i++ {
+				file[record+i] = binary.BigEndian.Uint16(sub[7+i*2:])
+			}
+			sub = sub[7+length*2:]
+		}
+		return append([]byte(nil), pdu...)
+	}
+	client := newTestClient(t, server)
+
+	err := client.WriteFileRecord(1, []FileRecord{
+		{FileNumber: 4, RecordNumber: 7, Data: []uint16{0x06AF, 0x04BE, 0x100D}},
+		{FileNumber: 5, RecordNumber: 0, Data: []uint16{0x1234}},
+	})
+	if err != nil {
+		t.Fatalf("WriteFileRecord failed: %v", err)
+	}
+
+	responses, err := client.ReadFileRecord(1, []FileRecordRequest{
+		{FileNumber: 4, RecordNumber: 7, RecordLength: 3},
+		{FileNumber: 5, RecordNumber: 0, RecordLength: 1},
+	})
+	if err != nil {
+		t.Fatalf("ReadFileRecord failed: %v", err)
+	}
+	if responses[0].Data[2] != 0x100D || responses[1].Data[0] != 0x1234 {
+		t.Errorf("Written records not read back: %v", responses)
+	}
+
+	// A mangled echo must be reported
+	server.mutex.Lock()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		echo := append([]byte(nil), pdu...)
+		echo[len(echo)-1] ^= 0xFF
+		return echo
+	}
+	server.mutex.Unlock()
+	if err := client.WriteFileRecord(1, []FileRecord{{FileNumber: 4, Data: []uint16{1}}}); err == nil {
+		t.Error("Expected error for mismatched echo")
+	}
+
+	if err := client.WriteFileRecord(1, []FileRecord{{FileNumber: 4}}); err == nil {
+		t.Error("Expected error for empty record data")
+	}
+}
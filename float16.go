@@ -0,0 +1,96 @@
+package modbus
+
+import "math"
+
+// ReadFloat16 reads an IEEE 754 half-precision float stored in a single
+// holding register and returns it widened to float32
+func (c *Client) ReadFloat16(slaveID byte, address uint16) (float32, error) {
+	registers, err := c.ReadHoldingRegisters(slaveID, address, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return float16ToFloat32(registers[0]), nil
+}
+
+// WriteFloat16 writes value to a single holding register as an IEEE 754
+// half-precision float. Values are rounded to the nearest representable
+// half (ties to even); magnitudes above 65504 become infinity.
+func (c *Client) WriteFloat16(slaveID byte, address uint16, value float32) error {
+	return c.WriteSingleRegister(slaveID, address, float32ToFloat16(value))
+}
+
+// float16ToFloat32 converts half-precision bits to a float32. Every half
+// value, including subnormals, infinities and NaN, is exactly representable.
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exponent := int(h>>10) & 0x1F
+	mantissa := uint32(h & 0x03FF)
+
+	switch exponent {
+	case 0:
+		if mantissa == 0 {
+			return math.Float32frombits(sign) // Signed zero
+		}
+		// Subnormal: shift the mantissa up until the implicit bit appears
+		exponent = -14
+		for mantissa&0x0400 == 0 {
+			mantissa <<= 1
+			exponent--
+		}
+		mantissa &= 0x03FF
+		return math.Float32frombits(sign | uint32(exponent+127)<<23 | mantissa<<13)
+
+	case 0x1F:
+		// Infinity or NaN, keeping the NaN payload
+		return math.Float32frombits(sign | 0x7F800000 | mantissa<<13)
+	}
+
+	return math.Float32frombits(sign | uint32(exponent-15+127)<<23 | mantissa<<13)
+}
+
+// float32ToFloat16 converts a float32 to half-precision bits, rounding to
+// nearest even and producing subnormals, infinities and NaN as appropriate
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exponent := int(bits>>23) & 0xFF
+	mantissa := bits & 0x007FFFFF
+
+	if exponent == 0xFF {
+		if mantissa == 0 {
+			return sign | 0x7C00 // Infinity
+		}
+		// NaN: keep the top payload bits and force a quiet NaN so the
+		// result can't collapse to infinity
+		return sign | 0x7E00 | uint16(mantissa>>13)
+	}
+
+	halfExponent := exponent - 127 + 15
+	if halfExponent >= 0x1F {
+		return sign | 0x7C00 // Too large, overflow to infinity
+	}
+
+	if halfExponent <= 0 {
+		if halfExponent < -10 {
+			return sign // Too small even for a subnormal, round to zero
+		}
+		// Subnormal: restore the implicit bit and shift into place
+		mantissa |= 0x00800000
+		shift := uint(14 - halfExponent)
+		halfMantissa := mantissa >> shift
+		remainder := mantissa & (1<<shift - 1)
+		halfway := uint32(1) << (shift - 1)
+		if remainder > halfway || (remainder == halfway && halfMantissa&1 == 1) {
+			halfMantissa++ // May carry into the smallest normal, which is correct
+		}
+		return sign | uint16(halfMantissa)
+	}
+
+	half := sign | uint16(halfExponent)<<10 | uint16(mantissa>>13)
+	remainder := mantissa & 0x1FFF
+	if remainder > 0x1000 || (remainder == 0x1000 && half&1 == 1) {
+		half++ // May carry into the exponent, up to infinity, which is correct
+	}
+	return half
+}
@@ -0,0 +1,93 @@
+package modbus
+
+import (
+	"math"
+	"testing"
+)
+
+// TestFloat16Conversion tests conversion of representative and edge case values
+func TestFloat16Conversion(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float32
+		bits  uint16
+	}{
+		{"zero", 0, 0x0000},
+		{"negative zero", float32(math.Copysign(0, -1)), 0x8000},
+		{"one", 1, 0x3C00},
+		{"minus two", -2, 0xC000},
+		{"one third", 0.333251953125, 0x3555},
+		{"max", 65504, 0x7BFF},
+		{"min normal", 6.103515625e-05, 0x0400},
+		{"max subnormal", 6.097555160522461e-05, 0x03FF},
+		{"min subnormal", 5.960464477539063e-08, 0x0001},
+		{"infinity", float32(math.Inf(1)), 0x7C00},
+		{"negative infinity", float32(math.Inf(-1)), 0xFC00},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := float32ToFloat16(tt.value); got != tt.bits {
+				t.Errorf("float32ToFloat16(%v) = 0x%04X, expected 0x%04X", tt.value, got, tt.bits)
+			}
+			got := float16ToFloat32(tt.bits)
+			if got != tt.value || math.Signbit(float64(got)) != math.Signbit(float64(tt.value)) {
+				t.Errorf("float16ToFloat32(0x%04X) = %v, expected %v", tt.bits, got, tt.value)
+			}
+		})
+	}
+}
+
+// TestFloat16Rounding tests rounding, overflow, underflow and NaN handling
+func TestFloat16Rounding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float32
+		bits  uint16
+	}{
+		{"tie rounds to even (down)", 1 + 1.0/2048, 0x3C00},
+		{"tie rounds to even (up)", 1 + 3.0/2048, 0x3C02},
+		{"above tie rounds up", 1 + 1.0/2048 + 1.0/16384, 0x3C01},
+		{"overflow", 70000, 0x7C00},
+		{"rounds up to infinity", 65520, 0x7C00},
+		{"subnormal tie to zero", 2.9802322387695312e-08, 0x0000},
+		{"subnormal rounds up", 4.470348358154297e-08, 0x0001},
+		{"underflow", 1e-10, 0x0000},
+		{"negative underflow", -1e-10, 0x8000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := float32ToFloat16(tt.value); got != tt.bits {
+				t.Errorf("float32ToFloat16(%v) = 0x%04X, expected 0x%04X", tt.value, got, tt.bits)
+			}
+		})
+	}
+
+	nan := float32ToFloat16(float32(math.NaN()))
+	if nan&0x7C00 != 0x7C00 || nan&0x03FF == 0 {
+		t.Errorf("Expected NaN encoding, got 0x%04X", nan)
+	}
+	if !math.IsNaN(float64(float16ToFloat32(0x7E00))) {
+		t.Error("Expected 0x7E00 to decode as NaN")
+	}
+}
+
+// TestReadWriteFloat16 tests the half-precision register round trip
+func TestReadWriteFloat16(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	for _, value := range []float32{1.5, -0.25, 100, 65504} {
+		if err := client.WriteFloat16(1, 3, value); err != nil {
+			t.Fatalf("WriteFloat16(%v) failed: %v", value, err)
+		}
+		got, err := client.ReadFloat16(1, 3)
+		if err != nil {
+			t.Fatalf("ReadFloat16 failed: %v", err)
+		}
+		if got != value {
+			t.Errorf("Expected %v after round trip, got %v", value, got)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The Encode functions build the complete MBAP framed request a Client would
+// send for the corresponding call, without sending it, for test fixtures and
+// offline checks. Quantities and address ranges are not validated, so they
+// can also produce frames a device should reject.
+
+// EncodeFrame wraps a PDU (function code followed by data) in an MBAP header
+// with protocol ID 0
+func EncodeFrame(transactionID uint16, slaveID byte, pdu []byte) []byte {
+	frame := make([]byte, 7+len(pdu))
+	putFrame(frame, transactionID, 0, slaveID, pdu)
+	return frame
+}
+
+// EncodeReadCoils encodes a Read Coils request (function code 0x01)
+func EncodeReadCoils(transactionID uint16, slaveID byte, address, quantity uint16) []byte {
+	return EncodeFrame(transactionID, slaveID, readRequestPDU(nil, FuncCodeReadCoils, address, quantity))
+}
+
+// EncodeReadDiscreteInputs encodes a Read Discrete Inputs request (function
+// code 0x02)
+func EncodeReadDiscreteInputs(transactionID uint16, slaveID byte, address, quantity uint16) []byte {
+	return EncodeFrame(transactionID, slaveID, readRequestPDU(nil, FuncCodeReadDiscreteInputs, address, quantity))
+}
+
+// EncodeReadHoldingRegisters encodes a Read Holding Registers request
+// (function code 0x03)
+func EncodeReadHoldingRegisters(transactionID uint16, slaveID byte, address, quantity uint16) []byte {
+	return EncodeFrame(transactionID, slaveID, readRequestPDU(nil, FuncCodeReadHoldingRegisters, address, quantity))
+}
+
+// EncodeReadInputRegisters encodes a Read Input Registers request (function
+// code 0x04)
+func EncodeReadInputRegisters(transactionID uint16, slaveID byte, address, quantity uint16) []byte {
+	return EncodeFrame(transactionID, slaveID, readRequestPDU(nil, FuncCodeReadInputRegisters, address, quantity))
+}
+
+// EncodeWriteSingleCoil encodes a Write Single Coil request (function code
+// 0x05)
+func EncodeWriteSingleCoil(transactionID uint16, slaveID byte, address uint16, value bool) []byte {
+	return EncodeFrame(transactionID, slaveID, writeSingleCoilPDU(address, value))
+}
+
+// EncodeWriteSingleRegister encodes a Write Single Register request
+// (function code 0x06)
+func EncodeWriteSingleRegister(transactionID uint16, slaveID byte, address, value uint16) []byte {
+	return EncodeFrame(transactionID, slaveID, writeSingleRegisterPDU(address, value))
+}
+
+// EncodeWriteMultipleCoils encodes a Write Multiple Coils request (function
+// code 0x0F)
+func EncodeWriteMultipleCoils(transactionID uint16, slaveID byte, address uint16, values []bool) []byte {
+	return EncodeFrame(transactionID, slaveID, writeMultipleCoilsPDU(nil, address, values))
+}
+
+// EncodeWriteMultipleRegisters encodes a Write Multiple Registers request
+// (function code 0x10)
+func EncodeWriteMultipleRegisters(transactionID uint16, slaveID byte, address uint16, values []uint16) []byte {
+	return EncodeFrame(transactionID, slaveID, writeMultipleRegistersPDU(nil, address, values))
+}
+
+// Response is a decoded MBAP response frame
+type Response struct {
+	TransactionID uint16
+	ProtocolID    uint16
+	UnitID        byte
+	FunctionCode  byte         // Function code with the exception bit cleared
+	Data          []byte       // PDU after the function code
+	Exception     *ModbusError // Set for exception responses
+}
+
+// DecodeResponse splits a captured response frame into its MBAP header
+// fields and PDU. The frame must be exactly one frame long, as its length
+// field declares.
+func DecodeResponse(frame []byte) (Response, error) {
+	if len(frame) < 8 {
+		return Response{}, fmt.Errorf("frame too short: %d bytes", len(frame))
+	}
+	length := int(binary.BigEndian.Uint16(frame[4:6]))
+	if length != len(frame)-6 {
+		return Response{}, fmt.Errorf("length field %d does not match %d bytes after the header", length, len(frame)-6)
+	}
+
+	response := Response{
+		TransactionID: binary.BigEndian.Uint16(frame[0:2]),
+		ProtocolID:    binary.BigEndian.Uint16(frame[2:4]),
+		UnitID:        frame[6],
+		FunctionCode:  frame[7] & 0x7F,
+		Data:          append([]byte(nil), frame[8:]...),
+	}
+	if frame[7]&0x80 != 0 {
+		if len(response.Data) < 1 {
+			return Response{}, fmt.Errorf("invalid exception response length")
+		}
+		response.Exception = newModbusError(frame[7:])
+	}
+
+	return response, nil
+}
+
+// Framer builds and parses the 7-byte MBAP header that precedes every PDU.
+// MBAPFramer follows the specification; a custom Framer can adapt the client
+// to hardware that fills in the header differently, e.g. a gateway whose
+// length field leaves out the unit ID.
+type Framer interface {
+	// EncodeHeader writes the header of a request carrying pduLength PDU
+	// bytes into header, which holds exactly 7 bytes
+	EncodeHeader(header []byte, transactionID, protocolID uint16, unitID byte, pduLength int)
+
+	// DecodeHeader parses a 7-byte response header and returns how many
+	// PDU bytes follow it. The client rejects a length below zero or above
+	// its MaxPDUSize as a transport error without reading further.
+	DecodeHeader(header []byte) (transactionID uint16, unitID byte, pduLength int, err error)
+}
+
+// MBAPFramer is the standard Framer: the length field counts the unit ID
+// and the PDU
+type MBAPFramer struct{}
+
+// EncodeHeader writes a standard MBAP header
+func (MBAPFramer) EncodeHeader(header []byte, transactionID, protocolID uint16, unitID byte, pduLength int) {
+	binary.BigEndian.PutUint16(header[0:2], transactionID)       // Transaction ID
+	binary.BigEndian.PutUint16(header[2:4], protocolID)          // Protocol ID (0 for Modbus)
+	binary.BigEndian.PutUint16(header[4:6], uint16(pduLength+1)) // Length
+	header[6] = unitID                                           // Unit ID
+}
+
+// DecodeHeader parses a standard MBAP header, rejecting length fields that
+// cannot belong to a valid frame
+func (MBAPFramer) DecodeHeader(header []byte) (uint16, byte, int, error) {
+	length := binary.BigEndian.Uint16(header[4:6])
+	if length < 1 || length > maxMBAPLength {
+		return 0, 0, 0, fmt.Errorf("invalid MBAP length %d: must be between 1 and %d", length, maxMBAPLength)
+	}
+	return binary.BigEndian.Uint16(header[0:2]), header[6], int(length) - 1, nil
+}
+
+// putFrame writes the standard MBAP header and pdu into frame, which must
+// hold exactly 7+len(pdu) bytes
+func putFrame(frame []byte, transactionID, protocolID uint16, slaveID byte, pdu []byte) {
+	MBAPFramer{}.EncodeHeader(frame[:7], transactionID, protocolID, slaveID, len(pdu))
+	copy(frame[7:], pdu)
+}
+
+// growPDU returns buf resized to size zeroed bytes, allocating a new buffer
+// only when buf is too small. A nil buf always allocates.
+func growPDU(buf []byte, size int) []byte {
+	if cap(buf) < size {
+		return make([]byte, size)
+	}
+	buf = buf[:size]
+	for i := range buf {
+		buf[i] = 0
+	}
+	return buf
+}
+
+// readRequestPDU builds the PDU shared by the bit and register read
+// functions, in buf if it is large enough
+func readRequestPDU(buf []byte, funcCode byte, address, quantity uint16) []byte {
+	pdu := growPDU(buf, 5)
+	pdu[0] = funcCode
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+	binary.BigEndian.PutUint16(pdu[3:5], quantity)
+	return pdu
+}
+
+// writeSingleCoilPDU builds a Write Single Coil PDU
+func writeSingleCoilPDU(address uint16, value bool) []byte {
+	pdu := make([]byte, 5)
+	pdu[0] = FuncCodeWriteSingleCoil
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+	if value {
+		binary.BigEndian.PutUint16(pdu[3:5], 0xFF00)
+	}
+	return pdu
+}
+
+// writeSingleRegisterPDU builds a Write Single Register PDU
+func writeSingleRegisterPDU(address, value uint16) []byte {
+	pdu := make([]byte, 5)
+	pdu[0] = FuncCodeWriteSingleRegister
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+	binary.BigEndian.PutUint16(pdu[3:5], value)
+	return pdu
+}
+
+// writeMultipleCoilsPDU builds a Write Multiple Coils PDU in buf if it is
+// large enough, packing the coils eight per byte with the first coil in the
+// least significant bit
+func writeMultipleCoilsPDU(buf []byte, address uint16, values []bool) []byte {
+	byteCount := (len(values) + 7) / 8
+	pdu := growPDU(buf, 6+byteCount)
+	pdu[0] = FuncCodeWriteMultipleCoils
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+	binary.BigEndian.PutUint16(pdu[3:5], uint16(len(values)))
+	pdu[5] = byte(byteCount)
+	for i, value := range values {
+		if value {
+			pdu[6+i/8] |= 1 << (i % 8)
+		}
+	}
+	return pdu
+}
+
+// packedCoilsPDU builds a Write Multiple Coils PDU from coil bytes already
+// packed least significant bit first
+func packedCoilsPDU(address, quantity uint16, packed []byte) []byte {
+	pdu := make([]byte, 6+len(packed))
+	pdu[0] = FuncCodeWriteMultipleCoils
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+	binary.BigEndian.PutUint16(pdu[3:5], quantity)
+	pdu[5] = byte(len(packed))
+	copy(pdu[6:], packed)
+	return pdu
+}
+
+// writeMultipleRegistersPDU builds a Write Multiple Registers PDU in buf if
+// it is large enough
+func writeMultipleRegistersPDU(buf []byte, address uint16, values []uint16) []byte {
+	pdu := growPDU(buf, 6+2*len(values))
+	pdu[0] = FuncCodeWriteMultipleRegisters
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+	binary.BigEndian.PutUint16(pdu[3:5], uint16(len(values)))
+	pdu[5] = byte(2 * len(values))
+	for i, value := range values {
+		binary.BigEndian.PutUint16(pdu[6+2*i:], value)
+	}
+	return pdu
+}
@@ -0,0 +1,252 @@
+package modbus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// TestEncodeFrames tests the encoded request frames against known bytes
+func TestEncodeFrames(t *testing.T) {
+	tests := []struct {
+		name     string
+		frame    []byte
+		expected []byte
+	}{
+		{
+			"read coils",
+			EncodeReadCoils(1, 1, 0x0013, 19),
+			[]byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x06, 0x01, 0x01, 0x00, 0x13, 0x00, 0x13},
+		},
+		{
+			"read discrete inputs",
+			EncodeReadDiscreteInputs(2, 1, 0x00C4, 22),
+			[]byte{0x00, 0x02, 0x00, 0x00, 0x00, 0x06, 0x01, 0x02, 0x00, 0xC4, 0x00, 0x16},
+		},
+		{
+			"read holding registers",
+			EncodeReadHoldingRegisters(0x1234, 17, 0x006B, 3),
+			[]byte{0x12, 0x34, 0x00, 0x00, 0x00, 0x06, 0x11, 0x03, 0x00, 0x6B, 0x00, 0x03},
+		},
+		{
+			"read input registers",
+			EncodeReadInputRegisters(3, 1, 0x0008, 1),
+			[]byte{0x00, 0x03, 0x00, 0x00, 0x00, 0x06, 0x01, 0x04, 0x00, 0x08, 0x00, 0x01},
+		},
+		{
+			"write single coil",
+			EncodeWriteSingleCoil(4, 1, 0x00AC, true),
+			[]byte{0x00, 0x04, 0x00, 0x00, 0x00, 0x06, 0x01, 0x05, 0x00, 0xAC, 0xFF, 0x00},
+		},
+		{
+			"write single register",
+			EncodeWriteSingleRegister(5, 1, 0x0001, 0x0003),
+			[]byte{0x00, 0x05, 0x00, 0x00, 0x00, 0x06, 0x01, 0x06, 0x00, 0x01, 0x00, 0x03},
+		},
+		{
+			"write multiple coils",
+			EncodeWriteMultipleCoils(6, 1, 0x0013, []bool{true, false, true, true, false, false, true, true, true, false}),
+			[]byte{0x00, 0x06, 0x00, 0x00, 0x00, 0x09, 0x01, 0x0F, 0x00, 0x13, 0x00, 0x0A, 0x02, 0xCD, 0x01},
+		},
+		{
+			"write multiple registers",
+			EncodeWriteMultipleRegisters(7, 1, 0x0001, []uint16{0x000A, 0x0102}),
+			[]byte{0x00, 0x07, 0x00, 0x00, 0x00, 0x0B, 0x01, 0x10, 0x00, 0x01, 0x00, 0x02, 0x04, 0x00, 0x0A, 0x01, 0x02},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !bytes.Equal(tt.frame, tt.expected) {
+				t.Errorf("Expected [% X], got [% X]", tt.expected, tt.frame)
+			}
+		})
+	}
+}
+
+// TestEncodeMatchesClient tests that encoded frames match what a client sends
+func TestEncodeMatchesClient(t *testing.T) {
+	var sent []byte
+	client := NewClientWithTransport(NewMemoryTransport(), ClientConfig{
+		OnTransaction: func(request, response []byte, err error) { sent = request },
+	})
+
+	values := []uint16{1, 2, 3}
+	if err := client.WriteMultipleRegisters(4, 100, values); err != nil {
+		t.Fatalf("WriteMultipleRegisters failed: %v", err)
+	}
+	expected := EncodeWriteMultipleRegisters(client.transactionID, 4, 100, values)
+	if !bytes.Equal(sent, expected) {
+		t.Errorf("Expected client to send [% X], got [% X]", expected, sent)
+	}
+}
+
+// TestDecodeResponse tests splitting captured response frames
+func TestDecodeResponse(t *testing.T) {
+	response, err := DecodeResponse([]byte{0x12, 0x34, 0x00, 0x00, 0x00, 0x07, 0x11, 0x03, 0x04, 0x02, 0x2B, 0x00, 0x64})
+	if err != nil {
+		t.Fatalf("DecodeResponse failed: %v", err)
+	}
+	if response.TransactionID != 0x1234 || response.UnitID != 0x11 || response.FunctionCode != FuncCodeReadHoldingRegisters {
+		t.Errorf("Unexpected header fields: %+v", response)
+	}
+	if !bytes.Equal(response.Data, []byte{0x04, 0x02, 0x2B, 0x00, 0x64}) || response.Exception != nil {
+		t.Errorf("Expected register payload and no exception, got %+v", response)
+	}
+
+	response, err = DecodeResponse([]byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x03, 0x01, 0x83, 0x02})
+	if err != nil {
+		t.Fatalf("DecodeResponse failed: %v", err)
+	}
+	if response.FunctionCode != FuncCodeReadHoldingRegisters || response.Exception == nil ||
+		response.Exception.ExceptionCode != ExceptionIllegalDataAddress {
+		t.Errorf("Expected illegal data address exception, got %+v", response)
+	}
+
+	// Vendor detail bytes after the exception code are kept
+	response, err = DecodeResponse([]byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x04, 0x01, 0x83, 0x02, 0x17})
+	if err != nil {
+		t.Fatalf("DecodeResponse failed: %v", err)
+	}
+	if response.Exception == nil || !bytes.Equal(response.Exception.Details, []byte{0x17}) {
+		t.Errorf("Expected exception details [17], got %+v", response.Exception)
+	}
+
+	invalid := [][]byte{
+		{0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x01},             // No PDU
+		{0x00, 0x01, 0x00, 0x00, 0x00, 0x05, 0x01, 0x03, 0x00}, // Truncated
+		{0x00, 0x01, 0x00, 0x00, 0x00, 0x02, 0x01, 0x83},       // Exception without a code
+	}
+	for _, frame := range invalid {
+		if _, err := DecodeResponse(frame); err == nil {
+			t.Errorf("Expected error decoding [% X]", frame)
+		}
+	}
+}
+
+// shortLengthFramer is a Framer for a gateway whose MBAP length field counts
+// only the PDU, leaving out the unit ID
+type shortLengthFramer struct{}
+
+func (shortLengthFramer) EncodeHeader(header []byte, transactionID, protocolID uint16, unitID byte, pduLength int) {
+	MBAPFramer{}.EncodeHeader(header, transactionID, protocolID, unitID, pduLength)
+	binary.BigEndian.PutUint16(header[4:6], uint16(pduLength))
+}
+
+func (shortLengthFramer) DecodeHeader(header []byte) (uint16, byte, int, error) {
+	return binary.BigEndian.Uint16(header[0:2]), header[6], int(binary.BigEndian.Uint16(header[4:6])), nil
+}
+
+// shortLengthGateway is a MemoryTransport behind a gateway that uses the
+// short length field in both directions
+type shortLengthGateway struct {
+	*MemoryTransport
+}
+
+func (g shortLengthGateway) Write(p []byte) (int, error) {
+	frame := append([]byte(nil), p...)
+	binary.BigEndian.PutUint16(frame[4:6], binary.BigEndian.Uint16(frame[4:6])+1)
+	return g.MemoryTransport.Write(frame)
+}
+
+func (g shortLengthGateway) Read(p []byte) (int, error) {
+	n, err := g.MemoryTransport.Read(p)
+	if n == 7 { // Header read
+		binary.BigEndian.PutUint16(p[4:6], binary.BigEndian.Uint16(p[4:6])-1)
+	}
+	return n, err
+}
+
+// TestCustomFramer tests talking to non-standard hardware through a Framer
+func TestCustomFramer(t *testing.T) {
+	device := NewMemoryTransport()
+	device.SetRegister(0, 42)
+	gateway := shortLengthGateway{device}
+
+	standard := NewClientWithTransport(gateway, ClientConfig{})
+	if _, err := standard.ReadHoldingRegisters(1, 0, 1); err == nil {
+		t.Error("Expected the standard framer to misread the gateway's frames")
+	}
+
+	device.Close()
+	device = NewMemoryTransport()
+	device.SetRegister(0, 42)
+	client := NewClientWithTransport(shortLengthGateway{device}, ClientConfig{Framer: shortLengthFramer{}})
+	registers, err := client.ReadHoldingRegisters(1, 0, 1)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if registers[0] != 42 {
+		t.Errorf("Expected 42, got %d", registers[0])
+	}
+	if err := client.WriteSingleRegister(1, 1, 7); err != nil || device.Register(1) != 7 {
+		t.Errorf("Expected write through the custom framer, got register %d (err %v)", device.Register(1), err)
+	}
+}
+
+// fixedLengthFramer is a faulty Framer that reports the same PDU length for
+// every response
+type fixedLengthFramer struct {
+	length int
+}
+
+func (fixedLengthFramer) EncodeHeader(header []byte, transactionID, protocolID uint16, unitID byte, pduLength int) {
+	MBAPFramer{}.EncodeHeader(header, transactionID, protocolID, unitID, pduLength)
+}
+
+func (f fixedLengthFramer) DecodeHeader(header []byte) (uint16, byte, int, error) {
+	return binary.BigEndian.Uint16(header[0:2]), header[6], f.length, nil
+}
+
+// TestFramerLengthLimit tests that the client bounds the PDU length returned
+// by any Framer
+func TestFramerLengthLimit(t *testing.T) {
+	tests := []struct {
+		length    int
+		maxPDU    int
+		expectErr bool
+	}{
+		{-1, 0, true},
+		{1 << 30, 0, true},
+		{MaxPDUSize + 1, 0, true},
+		{6, 5, true},
+		{4, 0, false}, // Read of one register: function code, byte count, two bytes
+	}
+
+	for _, tt := range tests {
+		client := NewClientWithTransport(NewMemoryTransport(), ClientConfig{
+			Framer:     fixedLengthFramer{tt.length},
+			MaxPDUSize: tt.maxPDU,
+		})
+		_, err := client.ReadHoldingRegisters(1, 0, 1)
+		if tt.expectErr {
+			if !isTransportError(err) {
+				t.Errorf("Length %d, limit %d: expected transport error, got %v", tt.length, tt.maxPDU, err)
+			}
+		} else if err != nil {
+			t.Errorf("Length %d: expected success, got %v", tt.length, err)
+		}
+		client.Close()
+	}
+}
+
+// TestMBAPFramer tests the standard header encoding and decoding
+func TestMBAPFramer(t *testing.T) {
+	header := make([]byte, 7)
+	MBAPFramer{}.EncodeHeader(header, 0x1234, 0, 0x11, 5)
+	if !bytes.Equal(header, []byte{0x12, 0x34, 0x00, 0x00, 0x00, 0x06, 0x11}) {
+		t.Errorf("Expected 12 34 00 00 00 06 11, got % X", header)
+	}
+
+	transactionID, unitID, pduLength, err := MBAPFramer{}.DecodeHeader(header)
+	if err != nil || transactionID != 0x1234 || unitID != 0x11 || pduLength != 5 {
+		t.Errorf("Expected (0x1234, 0x11, 5), got (0x%04X, 0x%02X, %d, %v)", transactionID, unitID, pduLength, err)
+	}
+
+	for _, length := range []uint16{0, 255} {
+		binary.BigEndian.PutUint16(header[4:6], length)
+		if _, _, _, err := (MBAPFramer{}).DecodeHeader(header); err == nil {
+			t.Errorf("Length %d: expected error", length)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package modbus
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ServerID is a device's answer to Report Server ID (function code 0x11)
+type ServerID struct {
+	ID      []byte // Device-specific server ID, ClientConfig.ServerIDLength bytes
+	Running bool   // Run indicator status
+	Data    []byte // Any additional device-specific data
+	Raw     []byte // All response data after the byte count, for other layouts
+}
+
+// HealthStatus summarizes whether a device answers and reports itself running
+type HealthStatus struct {
+	Reachable       bool          // The device answered the probe, possibly with an exception
+	Running         bool          // Run indicator, or Reachable when the device has none
+	ExceptionStatus byte          // Exception status outputs, zero if not supported
+	Latency         time.Duration // Round trip time of the probe
+}
+
+// ReportServerID reads the device's server ID and run indicator (function
+// code 0x11). The server ID itself is device specific: it is taken to be
+// ClientConfig.ServerIDLength bytes followed by the run indicator, and Raw
+// keeps the response data unparsed for devices laid out differently.
+func (c *Client) ReportServerID(slaveID byte) (ServerID, error) {
+	response, err := c.sendRequest(slaveID, []byte{FuncCodeReportServerID})
+	if err != nil {
+		return ServerID{}, err
+	}
+
+	if len(response) < 2 || int(response[1]) != len(response)-2 {
+		return ServerID{}, fmt.Errorf("invalid response length")
+	}
+	raw := append([]byte(nil), response[2:]...)
+	if len(raw) < c.serverIDLen+1 {
+		return ServerID{Raw: raw}, fmt.Errorf("response too short: %d bytes for a %d-byte server ID and run indicator", len(raw), c.serverIDLen)
+	}
+
+	return ServerID{
+		ID:      raw[:c.serverIDLen:c.serverIDLen],
+		Running: raw[c.serverIDLen] == 0xFF,
+		Data:    raw[c.serverIDLen+1:],
+		Raw:     raw,
+	}, nil
+}
+
+// Health probes a device with Read Exception Status and Report Server ID.
+// The device is Reachable only if it answers the first probe, with its
+// status or an exception; any other error is returned with an unreachable
+// status. Devices that don't implement Report Server ID, or whose answer
+// can't be parsed, are taken to be running whenever they are reachable. The
+// broadcast unit ID never answers, so it is rejected.
+func (c *Client) Health(slaveID byte) (HealthStatus, error) {
+	var status HealthStatus
+	if slaveID == BroadcastSlaveID {
+		return status, fmt.Errorf("cannot probe the broadcast slave ID %d", slaveID)
+	}
+
+	start := time.Now()
+	exceptionStatus, err := c.ReadExceptionStatus(slaveID)
+	status.Latency = time.Since(start)
+	var modbusErr *ModbusError
+	if err != nil && !errors.As(err, &modbusErr) {
+		return status, err
+	}
+	status.Reachable = true
+	status.ExceptionStatus = exceptionStatus
+
+	serverID, err := c.ReportServerID(slaveID)
+	switch {
+	case err == nil:
+		status.Running = serverID.Running
+	case isTransportError(err):
+		return status, err
+	default:
+		status.Running = true
+	}
+
+	return status, nil
+}
@@ -0,0 +1,157 @@
+package modbus
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// TestHealth tests combining the exception status and run indicator
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  func(unitID byte, pdu []byte) []byte
+		expected HealthStatus
+	}{
+		{
+			name: "running",
+			handler: func(unitID byte, pdu []byte) []byte {
+				switch pdu[0] {
+				case FuncCodeReadExceptionStatus:
+					return []byte{pdu[0], 0x05}
+				case FuncCodeReportServerID:
+					return []byte{pdu[0], 3, 0x2A, 0xFF, 0x01}
+				}
+				return nil
+			},
+			expected: HealthStatus{Reachable: true, Running: true, ExceptionStatus: 0x05},
+		},
+		{
+			name: "stopped",
+			handler: func(unitID byte, pdu []byte) []byte {
+				switch pdu[0] {
+				case FuncCodeReadExceptionStatus:
+					return []byte{pdu[0], 0x00}
+				case FuncCodeReportServerID:
+					return []byte{pdu[0], 2, 0x2A, 0x00}
+				}
+				return nil
+			},
+			expected: HealthStatus{Reachable: true, Running: false},
+		},
+		{
+			name: "neither function supported",
+			handler: func(unitID byte, pdu []byte) []byte {
+				return []byte{pdu[0] | 0x80, ExceptionIllegalFunction}
+			},
+			expected: HealthStatus{Reachable: true, Running: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewMockServer()
+			server.handler = tt.handler
+			client := newTestClient(t, server)
+
+			status, err := client.Health(1)
+			if err != nil {
+				t.Fatalf("Health failed: %v", err)
+			}
+			if status.Latency <= 0 {
+				t.Errorf("Expected a positive latency, got %v", status.Latency)
+			}
+			status.Latency = 0
+			if status != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, status)
+			}
+		})
+	}
+}
+
+// TestHealthUnreachable tests a device that never answers
+func TestHealthUnreachable(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: 50 * time.Millisecond})
+
+	status, err := client.Health(1)
+	if err == nil {
+		t.Error("Expected error for unreachable device")
+	}
+	if status.Reachable || status.Running {
+		t.Errorf("Expected unreachable status, got %+v", status)
+	}
+}
+
+// TestHealthBroadcast tests that unit 0 is refused before anything is sent
+// rather than reported reachable
+func TestHealthBroadcast(t *testing.T) {
+	server := NewMockServer()
+	var requests int
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		requests++
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	status, err := client.Health(BroadcastSlaveID)
+	if err == nil {
+		t.Error("Expected error probing the broadcast slave ID")
+	}
+	if status.Reachable || status.Running {
+		t.Errorf("Expected unreachable status for unit 0, got %+v", status)
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if requests != 0 {
+		t.Errorf("Expected no requests for unit 0, got %d", requests)
+	}
+}
+
+// TestReportServerID tests decoding the server ID and run indicator
+func TestReportServerID(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] == FuncCodeReportServerID {
+			return []byte{pdu[0], 4, 0x07, 0xFF, 'O', 'K'}
+		}
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	serverID, err := client.ReportServerID(1)
+	if err != nil {
+		t.Fatalf("ReportServerID failed: %v", err)
+	}
+	if !bytes.Equal(serverID.ID, []byte{0x07}) || !serverID.Running || string(serverID.Data) != "OK" {
+		t.Errorf("Expected ID 7, running, data OK, got %+v", serverID)
+	}
+	if !bytes.Equal(serverID.Raw, []byte{0x07, 0xFF, 'O', 'K'}) {
+		t.Errorf("Expected raw data 07 FF 4F 4B, got % X", serverID.Raw)
+	}
+
+	// A device with a three-byte ID
+	long := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second, ServerIDLength: 3})
+	serverID, err = long.ReportServerID(1)
+	if err != nil {
+		t.Fatalf("ReportServerID failed: %v", err)
+	}
+	if !bytes.Equal(serverID.ID, []byte{0x07, 0xFF, 'O'}) || serverID.Running || string(serverID.Data) != "" {
+		t.Errorf("Expected ID 07 FF 4F, stopped, no data, got %+v", serverID)
+	}
+
+	// Too short for the configured ID: an error, but the device answered
+	longer := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second, ServerIDLength: 4})
+	serverID, err = longer.ReportServerID(1)
+	if err == nil || isTransportError(err) || len(serverID.Raw) != 4 {
+		t.Errorf("Expected a parse error with the raw data, got %+v (err %v)", serverID, err)
+	}
+	status, err := longer.Health(1)
+	if err != nil || !status.Reachable || !status.Running {
+		t.Errorf("Expected reachable and running despite the parse error, got %+v (err %v)", status, err)
+	}
+}
@@ -0,0 +1,73 @@
+package modbus
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics receives an observation for every request a client exchanges with
+// a device, so request counts, latencies and error rates can be exported to
+// a monitoring system. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveRequest is called once per exchange with the function code, the
+	// time taken including any wait for the connection, and the result
+	ObserveRequest(funcCode byte, duration time.Duration, err error)
+}
+
+// NopMetrics discards all observations; it is the client default
+type NopMetrics struct{}
+
+// ObserveRequest does nothing
+func (NopMetrics) ObserveRequest(funcCode byte, duration time.Duration, err error) {}
+
+// FunctionMetrics summarizes the requests observed for one function code
+type FunctionMetrics struct {
+	Requests      int           // Requests observed
+	Errors        int           // Requests that returned an error, exceptions included
+	TotalDuration time.Duration // Sum of request durations
+	MaxDuration   time.Duration // Slowest request
+}
+
+// CountingMetrics is a simple in-memory Metrics implementation that keeps
+// per-function-code totals
+type CountingMetrics struct {
+	mutex     sync.Mutex
+	functions map[byte]*FunctionMetrics
+}
+
+// NewCountingMetrics creates an empty CountingMetrics
+func NewCountingMetrics() *CountingMetrics {
+	return &CountingMetrics{functions: make(map[byte]*FunctionMetrics)}
+}
+
+// ObserveRequest records one request
+func (m *CountingMetrics) ObserveRequest(funcCode byte, duration time.Duration, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	function := m.functions[funcCode]
+	if function == nil {
+		function = &FunctionMetrics{}
+		m.functions[funcCode] = function
+	}
+	function.Requests++
+	if err != nil {
+		function.Errors++
+	}
+	function.TotalDuration += duration
+	if duration > function.MaxDuration {
+		function.MaxDuration = duration
+	}
+}
+
+// Snapshot returns a copy of the totals keyed by function code
+func (m *CountingMetrics) Snapshot() map[byte]FunctionMetrics {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshot := make(map[byte]FunctionMetrics, len(m.functions))
+	for funcCode, function := range m.functions {
+		snapshot[funcCode] = *function
+	}
+	return snapshot
+}
@@ -0,0 +1,50 @@
+package modbus
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCountingMetrics tests per-function request and error counts
+func TestCountingMetrics(t *testing.T) {
+	server := NewMockServer()
+	metrics := NewCountingMetrics()
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second, Metrics: metrics})
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+			t.Fatalf("ReadHoldingRegisters failed: %v", err)
+		}
+	}
+	if err := client.WriteSingleRegister(1, 0, 5); err != nil {
+		t.Fatalf("WriteSingleRegister failed: %v", err)
+	}
+	if _, err := client.SendRawPDU(1, []byte{0x41, 0, 0, 0, 0}); err == nil {
+		t.Fatal("Expected exception for unsupported function")
+	}
+
+	snapshot := metrics.Snapshot()
+	tests := []struct {
+		funcCode byte
+		requests int
+		errors   int
+	}{
+		{FuncCodeReadHoldingRegisters, 3, 0},
+		{FuncCodeWriteSingleRegister, 1, 0},
+		{0x41, 1, 1},
+	}
+	for _, tt := range tests {
+		function := snapshot[tt.funcCode]
+		if function.Requests != tt.requests || function.Errors != tt.errors {
+			t.Errorf("Function 0x%02X: expected %d requests and %d errors, got %d and %d",
+				tt.funcCode, tt.requests, tt.errors, function.Requests, function.Errors)
+		}
+		if function.TotalDuration <= 0 || function.MaxDuration > function.TotalDuration {
+			t.Errorf("Function 0x%02X: implausible durations total=%v max=%v",
+				tt.funcCode, function.TotalDuration, function.MaxDuration)
+		}
+	}
+	if len(snapshot) != len(tests) {
+		t.Errorf("Expected %d function codes, got %d", len(tests), len(snapshot))
+	}
+}
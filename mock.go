@@ -0,0 +1,278 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// MemoryTransport is an in-memory Transport that behaves like a simple
+// Modbus device, so code built on Client can be tested without sockets. It
+// supports reading and writing coils and registers (function codes 0x01-0x06,
+// 0x0F, 0x10 and 0x16); discrete inputs share the coil table and input
+// registers share the holding register table. Frames must be written whole,
+// as Client does. FailOn programs exception responses for testing error
+// handling.
+type MemoryTransport struct {
+	mutex     sync.Mutex
+	coils     map[uint16]bool
+	registers map[uint16]uint16
+	failures  []injectedFailure
+	pending   []byte // response bytes not yet read
+	closed    bool
+}
+
+// injectedFailure is an exception response programmed with FailOn
+type injectedFailure struct {
+	funcCode      byte
+	address       uint16
+	exceptionCode byte
+}
+
+// NewMemoryTransport creates an in-memory device with all coils off and all
+// registers zero
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{
+		coils:     make(map[uint16]bool),
+		registers: make(map[uint16]uint16),
+	}
+}
+
+// SetCoil sets the coil at address
+func (m *MemoryTransport) SetCoil(address uint16, value bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.coils[address] = value
+}
+
+// Coil returns the coil at address
+func (m *MemoryTransport) Coil(address uint16) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.coils[address]
+}
+
+// SetRegister sets the register at address
+func (m *MemoryTransport) SetRegister(address, value uint16) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.registers[address] = value
+}
+
+// Register returns the register at address
+func (m *MemoryTransport) Register(address uint16) uint16 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.registers[address]
+}
+
+// FailOn makes requests with funcCode that touch address fail with
+// exceptionCode instead of being applied. A request touches every address in
+// its range, so a read of 0-9 fails on a failure programmed at 5. For
+// function codes without an address, such as Read Exception Status, address
+// is ignored. Later calls for the same function code and address replace
+// earlier ones.
+func (m *MemoryTransport) FailOn(funcCode byte, address uint16, exceptionCode byte) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i, failure := range m.failures {
+		if failure.funcCode == funcCode && failure.address == address {
+			m.failures[i].exceptionCode = exceptionCode
+			return
+		}
+	}
+	m.failures = append(m.failures, injectedFailure{funcCode, address, exceptionCode})
+}
+
+// ClearFailures removes every failure programmed with FailOn
+func (m *MemoryTransport) ClearFailures() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.failures = nil
+}
+
+// injectedException returns the exception response programmed for a request
+// PDU, or nil if it should be handled normally
+func (m *MemoryTransport) injectedException(pdu []byte) []byte {
+	if len(pdu) == 0 {
+		return nil
+	}
+	for _, failure := range m.failures {
+		if failure.funcCode != pdu[0] {
+			continue
+		}
+		first, count, ok := requestRange(pdu)
+		if !ok || uint32(failure.address) >= uint32(first) && uint32(failure.address) < uint32(first)+uint32(count) {
+			return []byte{pdu[0] | 0x80, failure.exceptionCode}
+		}
+	}
+	return nil
+}
+
+// requestRange returns the first address and number of addresses a request
+// PDU touches, or ok false for function codes that carry no address
+func requestRange(pdu []byte) (first, count uint16, ok bool) {
+	switch pdu[0] {
+	case FuncCodeReadCoils, FuncCodeReadDiscreteInputs,
+		FuncCodeReadHoldingRegisters, FuncCodeReadInputRegisters,
+		FuncCodeWriteMultipleCoils, FuncCodeWriteMultipleRegisters:
+		if len(pdu) < 5 {
+			return 0, 0, false
+		}
+		return binary.BigEndian.Uint16(pdu[1:3]), binary.BigEndian.Uint16(pdu[3:5]), true
+	case FuncCodeWriteSingleCoil, FuncCodeWriteSingleRegister, FuncCodeMaskWriteRegister:
+		if len(pdu) < 3 {
+			return 0, 0, false
+		}
+		return binary.BigEndian.Uint16(pdu[1:3]), 1, true
+	}
+	return 0, 0, false
+}
+
+// Write accepts one complete MBAP request frame and queues the response.
+// Broadcasts (unit ID 0) are applied but not answered.
+func (m *MemoryTransport) Write(p []byte) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed {
+		return 0, net.ErrClosed
+	}
+	if len(p) < 8 || int(binary.BigEndian.Uint16(p[4:6])) != len(p)-6 {
+		return 0, fmt.Errorf("memory transport: incomplete request frame")
+	}
+
+	unitID := p[6]
+	response := m.injectedException(p[7:])
+	if response == nil {
+		response = handleMemoryDevice(m.coils, m.registers, p[7:])
+	}
+	if unitID == BroadcastSlaveID || len(response) == 0 {
+		return len(p), nil
+	}
+
+	frame := make([]byte, 7, 7+len(response))
+	copy(frame, p[0:4])
+	binary.BigEndian.PutUint16(frame[4:6], uint16(len(response)+1))
+	frame[6] = unitID
+	m.pending = append(m.pending, append(frame, response...)...)
+
+	return len(p), nil
+}
+
+// Read returns queued response bytes. With nothing queued it fails at once
+// with os.ErrDeadlineExceeded, as a real device that never answered would
+// once the read deadline passed.
+func (m *MemoryTransport) Read(p []byte) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed {
+		return 0, net.ErrClosed
+	}
+	if len(m.pending) == 0 {
+		return 0, os.ErrDeadlineExceeded
+	}
+
+	n := copy(p, m.pending)
+	m.pending = m.pending[n:]
+	return n, nil
+}
+
+// Close marks the transport closed; later reads and writes fail
+func (m *MemoryTransport) Close() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.closed = true
+	m.pending = nil
+	return nil
+}
+
+// SetReadDeadline is a no-op; reads never block
+func (m *MemoryTransport) SetReadDeadline(t time.Time) error { return nil }
+
+// SetWriteDeadline is a no-op; writes never block
+func (m *MemoryTransport) SetWriteDeadline(t time.Time) error { return nil }
+
+// handleMemoryDevice applies a request PDU to coil and register tables and
+// returns the response PDU, or nil for an empty request. The caller is
+// responsible for locking the tables.
+func handleMemoryDevice(coils map[uint16]bool, registers map[uint16]uint16, pdu []byte) []byte {
+	if len(pdu) == 0 {
+		return nil
+	}
+	exception := func(code byte) []byte { return []byte{pdu[0] | 0x80, code} }
+	if len(pdu) < 5 {
+		return exception(ExceptionIllegalDataValue)
+	}
+	address := binary.BigEndian.Uint16(pdu[1:3])
+	value := binary.BigEndian.Uint16(pdu[3:5])
+
+	switch pdu[0] {
+	case FuncCodeReadCoils, FuncCodeReadDiscreteInputs:
+		if value == 0 || value > 2000 {
+			return exception(ExceptionIllegalDataValue)
+		}
+		response := make([]byte, 2+(value+7)/8)
+		response[0] = pdu[0]
+		response[1] = byte((value + 7) / 8)
+		for i := uint16(0); i < value; i++ {
+			if coils[address+i] {
+				response[2+i/8] |= 1 << (i % 8)
+			}
+		}
+		return response
+
+	case FuncCodeReadHoldingRegisters, FuncCodeReadInputRegisters:
+		if value == 0 || value > 125 {
+			return exception(ExceptionIllegalDataValue)
+		}
+		response := make([]byte, 2+value*2)
+		response[0] = pdu[0]
+		response[1] = byte(value * 2)
+		for i := uint16(0); i < value; i++ {
+			binary.BigEndian.PutUint16(response[2+i*2:], registers[address+i])
+		}
+		return response
+
+	case FuncCodeWriteSingleCoil:
+		coils[address] = value == 0xFF00
+		return append([]byte(nil), pdu...)
+
+	case FuncCodeWriteSingleRegister:
+		registers[address] = value
+		return append([]byte(nil), pdu...)
+
+	case FuncCodeWriteMultipleCoils:
+		if len(pdu) < 6+int(value+7)/8 {
+			return exception(ExceptionIllegalDataValue)
+		}
+		for i := uint16(0); i < value; i++ {
+			coils[address+i] = pdu[6+i/8]&(1<<(i%8)) != 0
+		}
+		return append([]byte(nil), pdu[0:5]...)
+
+	case FuncCodeWriteMultipleRegisters:
+		if len(pdu) < 6+int(value)*2 {
+			return exception(ExceptionIllegalDataValue)
+		}
+		for i := uint16(0); i < value; i++ {
+			registers[address+i] = binary.BigEndian.Uint16(pdu[6+i*2:])
+		}
+		return append([]byte(nil), pdu[0:5]...)
+
+	case FuncCodeMaskWriteRegister:
+		if len(pdu) < 7 {
+			return exception(ExceptionIllegalDataValue)
+		}
+		orMask := binary.BigEndian.Uint16(pdu[5:7])
+		registers[address] = registers[address]&value | orMask&^value
+		return append([]byte(nil), pdu...)
+	}
+
+	return exception(ExceptionIllegalFunction)
+}
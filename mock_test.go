@@ -0,0 +1,259 @@
+package modbus
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// TestMemoryTransport tests a client talking to an in-memory device
+func TestMemoryTransport(t *testing.T) {
+	device := NewMemoryTransport()
+	device.SetRegister(0, 1234)
+	device.SetCoil(3, true)
+
+	client := NewClientWithTransport(device, ClientConfig{})
+	defer client.Close()
+
+	registers, err := client.ReadHoldingRegisters(1, 0, 2)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if registers[0] != 1234 || registers[1] != 0 {
+		t.Errorf("Expected [1234 0], got %v", registers)
+	}
+
+	coils, err := client.ReadCoils(1, 0, 4)
+	if err != nil {
+		t.Fatalf("ReadCoils failed: %v", err)
+	}
+	if coils[3] != true || coils[0] != false {
+		t.Errorf("Expected only coil 3 on, got %v", coils)
+	}
+
+	if err := client.WriteMultipleRegisters(1, 10, []uint16{7, 8}); err != nil {
+		t.Fatalf("WriteMultipleRegisters failed: %v", err)
+	}
+	if device.Register(10) != 7 || device.Register(11) != 8 {
+		t.Errorf("Expected registers 10-11 = [7 8], got [%d %d]", device.Register(10), device.Register(11))
+	}
+
+	if err := client.WriteSingleCoil(BroadcastSlaveID, 5, true); err != nil {
+		t.Fatalf("Broadcast WriteSingleCoil failed: %v", err)
+	}
+	if !device.Coil(5) {
+		t.Error("Expected broadcast write to be applied")
+	}
+
+	// Unsupported functions come back as exceptions
+	_, err = client.SendRawPDU(1, []byte{0x41, 0x00, 0x00, 0x00, 0x00})
+	var modbusErr *ModbusError
+	if !errors.As(err, &modbusErr) || modbusErr.ExceptionCode != ExceptionIllegalFunction {
+		t.Errorf("Expected illegal function exception, got %v", err)
+	}
+
+	if err := client.Reconnect(); err == nil {
+		t.Error("Expected Reconnect to fail on a caller-supplied transport")
+	}
+}
+
+// TestMemoryTransportRead tests reads with no queued response
+func TestMemoryTransportRead(t *testing.T) {
+	device := NewMemoryTransport()
+
+	if _, err := device.Read(make([]byte, 7)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded with nothing queued, got %v", err)
+	}
+
+	device.Close()
+	if _, err := device.Write([]byte{0, 1, 0, 0, 0, 6, 1, 3, 0, 0, 0, 1}); err == nil {
+		t.Error("Expected write to fail after close")
+	}
+}
+
+// TestTransactionHookFramesRetained tests that frames handed to the
+// transaction hook are not overwritten by later requests
+func TestTransactionHookFramesRetained(t *testing.T) {
+	var requests [][]byte
+	client := NewClientWithTransport(NewMemoryTransport(), ClientConfig{
+		OnTransaction: func(request, response []byte, err error) {
+			requests = append(requests, request)
+		},
+	})
+
+	client.ReadHoldingRegisters(1, 0, 1)
+	client.ReadHoldingRegisters(2, 5, 3)
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 hook calls, got %d", len(requests))
+	}
+	if requests[0][6] != 1 || requests[0][9] != 0 || requests[0][11] != 1 {
+		t.Errorf("First request frame was modified: % X", requests[0])
+	}
+}
+
+// BenchmarkMemoryTransportRead measures a full register read round trip
+// without network overhead, so allocations in the request path stand out
+func BenchmarkMemoryTransportRead(b *testing.B) {
+	client := NewClientWithTransport(NewMemoryTransport(), ClientConfig{})
+	defer client.Close()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.ReadHoldingRegisters(1, 0, 10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkMemoryTransportReadInto is BenchmarkMemoryTransportRead decoding
+// into a reused slice
+func BenchmarkMemoryTransportReadInto(b *testing.B) {
+	client := NewClientWithTransport(NewMemoryTransport(), ClientConfig{})
+	defer client.Close()
+
+	registers := make([]uint16, 10)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.ReadHoldingRegistersInto(1, 0, 10, registers); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkBatch is a mix of reads and writes for the batch benchmarks
+var benchmarkBatch = []BatchOperation{
+	{Operation: "read_holding", SlaveID: 1, Address: 0, Quantity: 10},
+	{Operation: "read_input", SlaveID: 1, Address: 20, Quantity: 10},
+	{Operation: "read_coils", SlaveID: 1, Address: 0, Quantity: 16},
+	{Operation: "write_registers", SlaveID: 1, Address: 40, Values: []uint16{1, 2, 3, 4}},
+	{Operation: "write_coils", SlaveID: 1, Address: 40, Values: []bool{true, false, true}},
+}
+
+// BenchmarkExecuteBatch measures a batch against the same operations made
+// one call at a time, to show the buffers the batch shares
+func BenchmarkExecuteBatch(b *testing.B) {
+	client := NewClientWithTransport(NewMemoryTransport(), ClientConfig{})
+	defer client.Close()
+
+	b.Run("batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, result := range client.ExecuteBatch(benchmarkBatch) {
+				if result.Error != nil {
+					b.Fatal(result.Error)
+				}
+			}
+		}
+	})
+
+	b.Run("calls", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			results := make([]BatchResult, len(benchmarkBatch))
+			for j, op := range benchmarkBatch {
+				if results[j] = client.executeOperation(op, nil); results[j].Error != nil {
+					b.Fatal(results[j].Error)
+				}
+			}
+		}
+	})
+}
+
+// TestExecuteBatchBuffers tests that batch results decoded into shared
+// backings stay independent of each other and of later batches
+func TestExecuteBatchBuffers(t *testing.T) {
+	device := NewMemoryTransport()
+	for i := uint16(0); i < 4; i++ {
+		device.SetRegister(i, 100+i)
+		device.SetCoil(i, i%2 == 0)
+	}
+	client := NewClientWithTransport(device, ClientConfig{})
+	defer client.Close()
+
+	operations := []BatchOperation{
+		{Operation: "read_holding", SlaveID: 1, Address: 0, Quantity: 2},
+		{Operation: "read_holding", SlaveID: 1, Address: 0, Quantity: 200}, // invalid
+		{Operation: "write_registers", SlaveID: 1, Address: 2, Values: []uint16{7, 8}},
+		{Operation: "read_holding", SlaveID: 1, Address: 2, Quantity: 2},
+		{Operation: "read_coils", SlaveID: 1, Address: 0, Quantity: 3},
+		{Operation: "read_coils", SlaveID: 1, Address: 1, Quantity: 3},
+	}
+	results := client.ExecuteBatch(operations)
+	if results[1].Error == nil {
+		t.Error("Expected error for invalid quantity, got nil")
+	}
+
+	first := results[0].Values.([]uint16)
+	second := results[3].Values.([]uint16)
+	if cap(first) != len(first) {
+		t.Errorf("Expected result capacity %d, got %d", len(first), cap(first))
+	}
+	first[0] = 0
+	if second[0] != 7 || second[1] != 8 {
+		t.Errorf("Expected [7 8], got %v", second)
+	}
+
+	client.ExecuteBatch(operations) // Must not overwrite earlier results
+	expected := [][]bool{{true, false, true}, {false, true, false}}
+	for i, index := range []int{4, 5} {
+		if results[index].Error != nil {
+			t.Fatalf("read_coils failed: %v", results[index].Error)
+		}
+		coils := results[index].Values.([]bool)
+		for j, coil := range coils {
+			if coil != expected[i][j] {
+				t.Errorf("Expected coils %v, got %v", expected[i], coils)
+				break
+			}
+		}
+	}
+}
+
+// TestMemoryTransportFailOn tests programmed exception responses
+func TestMemoryTransportFailOn(t *testing.T) {
+	device := NewMemoryTransport()
+	device.FailOn(FuncCodeReadHoldingRegisters, 5, ExceptionIllegalDataAddress)
+	device.FailOn(FuncCodeWriteSingleRegister, 7, ExceptionSlaveDeviceBusy)
+	device.FailOn(FuncCodeReadExceptionStatus, 0, ExceptionSlaveDeviceFailure)
+	client := NewClientWithTransport(device, ClientConfig{})
+
+	tests := []struct {
+		name     string
+		call     func() error
+		expected ExceptionCode // 0 for success
+	}{
+		{"read covering address", func() error { _, err := client.ReadHoldingRegisters(1, 0, 10); return err }, ExceptionIllegalDataAddress},
+		{"read at address", func() error { _, err := client.ReadHoldingRegisters(1, 5, 1); return err }, ExceptionIllegalDataAddress},
+		{"read before address", func() error { _, err := client.ReadHoldingRegisters(1, 0, 5); return err }, 0},
+		{"read after address", func() error { _, err := client.ReadHoldingRegisters(1, 6, 4); return err }, 0},
+		{"other function", func() error { _, err := client.ReadInputRegisters(1, 5, 1); return err }, 0},
+		{"single write", func() error { return client.WriteSingleRegister(1, 7, 1) }, ExceptionSlaveDeviceBusy},
+		{"no address", func() error { _, err := client.ReadExceptionStatus(1); return err }, ExceptionSlaveDeviceFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if tt.expected == 0 {
+				if err != nil {
+					t.Errorf("Expected success, got %v", err)
+				}
+				return
+			}
+			var modbusErr *ModbusError
+			if !errors.As(err, &modbusErr) || modbusErr.Code() != tt.expected {
+				t.Errorf("Expected exception %v, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	if device.Register(7) != 0 {
+		t.Error("Expected the failed write not to be applied")
+	}
+
+	device.ClearFailures()
+	if _, err := client.ReadHoldingRegisters(1, 0, 10); err != nil {
+		t.Errorf("Expected success after ClearFailures, got %v", err)
+	}
+}
@@ -1,10 +1,20 @@
 package modbus
 
 import (
+	"context"
+	cryptorand "crypto/rand"
+	"crypto/tls"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"math/rand"
 	"net"
+	"os"
 	"sync"
+	"syscall"
 	"time"
 	"unsafe"
 )
@@ -17,142 +27,1398 @@ const (
 	FuncCodeReadInputRegisters     = 0x04
 	FuncCodeWriteSingleCoil        = 0x05
 	FuncCodeWriteSingleRegister    = 0x06
+	FuncCodeReadExceptionStatus    = 0x07
+	FuncCodeDiagnostics            = 0x08
 	FuncCodeWriteMultipleCoils     = 0x0F
 	FuncCodeWriteMultipleRegisters = 0x10
+	FuncCodeReportServerID         = 0x11
+	FuncCodeReadFileRecord         = 0x14
+	FuncCodeWriteFileRecord        = 0x15
+	FuncCodeMaskWriteRegister      = 0x16
+	FuncCodeReadFIFOQueue          = 0x18
 )
 
+// maxFIFOCount is the largest number of queued registers a FIFO read may return
+const maxFIFOCount = 31
+
+// maxMBAPLength is the largest valid MBAP length field: the unit ID plus a
+// 253-byte PDU, which fills the 260-byte Modbus TCP frame
+const maxMBAPLength = 254
+
 // Exception codes
 const (
-	ExceptionIllegalFunction    = 0x01
-	ExceptionIllegalDataAddress = 0x02
-	ExceptionIllegalDataValue   = 0x03
-	ExceptionSlaveDeviceFailure = 0x04
+	ExceptionIllegalFunction     = 0x01
+	ExceptionIllegalDataAddress  = 0x02
+	ExceptionIllegalDataValue    = 0x03
+	ExceptionSlaveDeviceFailure  = 0x04
+	ExceptionAcknowledge         = 0x05
+	ExceptionSlaveDeviceBusy     = 0x06
+	ExceptionNegativeAcknowledge = 0x07
+	ExceptionMemoryParityError   = 0x08
+
+	ExceptionGatewayPathUnavailable = 0x0A
+	ExceptionGatewayTargetFailed    = 0x0B
 )
 
+// ExceptionCode is a Modbus exception code as returned by a device
+type ExceptionCode byte
+
+// String returns the specification name of the exception code
+func (c ExceptionCode) String() string {
+	switch c {
+	case ExceptionIllegalFunction:
+		return "illegal function"
+	case ExceptionIllegalDataAddress:
+		return "illegal data address"
+	case ExceptionIllegalDataValue:
+		return "illegal data value"
+	case ExceptionSlaveDeviceFailure:
+		return "slave device failure"
+	case ExceptionAcknowledge:
+		return "acknowledge"
+	case ExceptionSlaveDeviceBusy:
+		return "slave device busy"
+	case ExceptionNegativeAcknowledge:
+		return "negative acknowledge"
+	case ExceptionMemoryParityError:
+		return "memory parity error"
+	case ExceptionGatewayPathUnavailable:
+		return "gateway path unavailable"
+	case ExceptionGatewayTargetFailed:
+		return "gateway target device failed to respond"
+	default:
+		return fmt.Sprintf("unknown exception 0x%02X", byte(c))
+	}
+}
+
+// Sentinel errors matched by errors.Is against a *ModbusError carrying the
+// corresponding exception code
+var (
+	ErrIllegalFunction        = errors.New("illegal function")
+	ErrIllegalDataAddress     = errors.New("illegal data address")
+	ErrIllegalDataValue       = errors.New("illegal data value")
+	ErrSlaveDeviceFailure     = errors.New("slave device failure")
+	ErrAcknowledge            = errors.New("acknowledge")
+	ErrSlaveDeviceBusy        = errors.New("slave device busy")
+	ErrNegativeAcknowledge    = errors.New("negative acknowledge")
+	ErrMemoryParityError      = errors.New("memory parity error")
+	ErrGatewayPathUnavailable = errors.New("gateway path unavailable")
+	ErrGatewayTargetFailed    = errors.New("gateway target device failed to respond")
+)
+
+// ErrAddressRange is matched by errors.Is against an *AddressRangeError
+var ErrAddressRange = errors.New("address range overflow")
+
+// AddressRangeError reports a request whose range runs past the end of the
+// 16-bit address space, e.g. 10 registers starting at 65530. Such requests
+// are rejected before they are sent.
+type AddressRangeError struct {
+	Address  uint16 // First address of the range
+	Quantity int    // Number of coils or registers requested
+}
+
+func (e *AddressRangeError) Error() string {
+	return fmt.Sprintf("address range overflow: address %d + quantity %d exceeds 65536", e.Address, e.Quantity)
+}
+
+// Is reports whether target is ErrAddressRange
+func (e *AddressRangeError) Is(target error) bool {
+	return target == ErrAddressRange
+}
+
+// ErrIdleClosed is returned by requests on a client whose connection was
+// closed by IdleTimeout and not reconnected
+var ErrIdleClosed = errors.New("connection idle-closed")
+
+// Transport failure causes, matched by errors.Is against errors from sending
+// a request or reading its response
+var (
+	ErrConnectionClosed = errors.New("connection closed") // The peer or the client closed the connection
+	ErrTimeout          = errors.New("request timed out") // A read or write deadline passed
+)
+
+// hookError is a failed OnReconnect hook. Retrying the reconnect would only
+// run the hook again, so it ends any retries at once.
+type hookError struct {
+	err error
+}
+
+func (e *hookError) Error() string {
+	return "reconnect hook failed: " + e.err.Error()
+}
+
+func (e *hookError) Unwrap() error {
+	return e.err
+}
+
+// connError is a failed read or write on the connection. It unwraps to the
+// underlying error and also matches ErrConnectionClosed or ErrTimeout when
+// either was the cause.
+type connError struct {
+	op  string // What was being done, e.g. "failed to send request"
+	err error
+}
+
+func (e *connError) Error() string {
+	return e.op + ": " + e.err.Error()
+}
+
+func (e *connError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether target is the cause sentinel for the underlying error
+func (e *connError) Is(target error) bool {
+	switch target {
+	case ErrTimeout:
+		var netErr net.Error
+		return errors.Is(e.err, os.ErrDeadlineExceeded) || errors.As(e.err, &netErr) && netErr.Timeout()
+	case ErrConnectionClosed:
+		return errors.Is(e.err, io.EOF) || errors.Is(e.err, io.ErrUnexpectedEOF) ||
+			errors.Is(e.err, net.ErrClosed) || errors.Is(e.err, syscall.ECONNRESET) ||
+			errors.Is(e.err, syscall.EPIPE)
+	}
+	return false
+}
+
+// exceptionSentinels maps exception codes to their sentinel errors
+var exceptionSentinels = map[byte]error{
+	ExceptionIllegalFunction:        ErrIllegalFunction,
+	ExceptionIllegalDataAddress:     ErrIllegalDataAddress,
+	ExceptionIllegalDataValue:       ErrIllegalDataValue,
+	ExceptionSlaveDeviceFailure:     ErrSlaveDeviceFailure,
+	ExceptionAcknowledge:            ErrAcknowledge,
+	ExceptionSlaveDeviceBusy:        ErrSlaveDeviceBusy,
+	ExceptionNegativeAcknowledge:    ErrNegativeAcknowledge,
+	ExceptionMemoryParityError:      ErrMemoryParityError,
+	ExceptionGatewayPathUnavailable: ErrGatewayPathUnavailable,
+	ExceptionGatewayTargetFailed:    ErrGatewayTargetFailed,
+}
+
 // ModbusError represents a Modbus exception
 type ModbusError struct {
 	FunctionCode  byte
 	ExceptionCode byte
+
+	// Details holds any vendor-specific bytes some devices append after the
+	// exception code, or nil for a standard exception response
+	Details []byte
 }
 
 func (e *ModbusError) Error() string {
+	if len(e.Details) > 0 {
+		return fmt.Sprintf("Modbus exception: function=0x%02X, exception=0x%02X, details=% X",
+			e.FunctionCode, e.ExceptionCode, e.Details)
+	}
 	return fmt.Sprintf("Modbus exception: function=0x%02X, exception=0x%02X",
 		e.FunctionCode, e.ExceptionCode)
 }
 
-// Client represents a Modbus TCP client
+// newModbusError builds the error for an exception response PDU of at least
+// two bytes, keeping any vendor detail bytes after the exception code
+func newModbusError(pdu []byte) *ModbusError {
+	err := &ModbusError{
+		FunctionCode:  pdu[0] & 0x7F,
+		ExceptionCode: pdu[1],
+	}
+	if len(pdu) > 2 {
+		err.Details = append([]byte(nil), pdu[2:]...)
+	}
+	return err
+}
+
+// Is reports whether target is the sentinel error for this exception code,
+// so errors.Is(err, ErrIllegalDataAddress) works on wrapped exceptions
+func (e *ModbusError) Is(target error) bool {
+	sentinel, ok := exceptionSentinels[e.ExceptionCode]
+	return ok && target == sentinel
+}
+
+// Code returns the exception code as an ExceptionCode
+func (e *ModbusError) Code() ExceptionCode {
+	return ExceptionCode(e.ExceptionCode)
+}
+
+// IsIllegalFunction reports whether the device does not support the function
+func (e *ModbusError) IsIllegalFunction() bool {
+	return e.ExceptionCode == ExceptionIllegalFunction
+}
+
+// IsIllegalAddress reports whether the requested address range is not valid
+// on the device
+func (e *ModbusError) IsIllegalAddress() bool {
+	return e.ExceptionCode == ExceptionIllegalDataAddress
+}
+
+// IsIllegalDataValue reports whether a value in the request was rejected
+func (e *ModbusError) IsIllegalDataValue() bool {
+	return e.ExceptionCode == ExceptionIllegalDataValue
+}
+
+// IsSlaveDeviceFailure reports whether the device failed while performing
+// the request
+func (e *ModbusError) IsSlaveDeviceFailure() bool {
+	return e.ExceptionCode == ExceptionSlaveDeviceFailure
+}
+
+// IsBusy reports whether the device is busy with a long-running command and
+// the request should be retried later
+func (e *ModbusError) IsBusy() bool {
+	return e.ExceptionCode == ExceptionSlaveDeviceBusy
+}
+
+// IsGatewayPathUnavailable reports whether a gateway could not route the
+// request to its target
+func (e *ModbusError) IsGatewayPathUnavailable() bool {
+	return e.ExceptionCode == ExceptionGatewayPathUnavailable
+}
+
+// IsGatewayTimeout reports whether a gateway's target device failed to respond
+func (e *ModbusError) IsGatewayTimeout() bool {
+	return e.ExceptionCode == ExceptionGatewayTargetFailed
+}
+
+// BroadcastSlaveID is the unit ID addressing every slave at once. Only write
+// functions may be broadcast and slaves never respond to them.
+const BroadcastSlaveID = 0
+
+// MaxPDUSize is the largest PDU the Modbus specification allows, in bytes
+const MaxPDUSize = 253
+
+// TCPUnitID is the unit ID conventionally sent to native Modbus/TCP devices,
+// which are addressed by IP and ignore the unit ID
+const TCPUnitID = 0xFF
+
+// recentSlaveWindow is the number of most recent requests whose slave IDs
+// are remembered for diagnostics
+const recentSlaveWindow = 32
+
+// flushWait is how long Flush waits for more stale bytes to arrive
+const flushWait = 20 * time.Millisecond
+
+// Transport is the byte stream a Client exchanges MBAP frames over. Any
+// net.Conn satisfies it.
+type Transport interface {
+	Read(p []byte) (n int, err error)
+	Write(p []byte) (n int, err error)
+	Close() error
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
+// Client represents a Modbus TCP client.
+//
+// A Client is safe for concurrent use by multiple goroutines. Requests on
+// one client are serialized over its single connection, so each response is
+// matched to the request that produced it; use a ConnectionPool to run
+// requests in parallel. Methods that only inspect the client, such as
+// Connected, ErrorRates and LastSlaveID, don't wait for a request in flight.
+// Read-modify-write helpers such as WriteBitField are atomic with respect to
+// each other on the same client, but not with respect to other clients or
+// plain writes to the same register.
 type Client struct {
-	conn          net.Conn
+	conn          Transport // replaced on reconnect while holding both mutex and statsMutex
+	ownTransport  bool      // conn was supplied by the caller and cannot be redialed
+	address       string
 	timeout       time.Duration
 	transactionID uint16
 	mutex         sync.Mutex
+	onTransaction func(request, response []byte, err error)
+	probeAddress  uint16
+	autoReconnect bool
+	onReconnect   func(c *Client) error
+	tlsConfig     *tls.Config
+	metrics       Metrics
+	dialer        ContextDialer
+	network       string
+	keepAlive     time.Duration
+	preferSingle  bool
+	verifyDelay   time.Duration
+	ignoreTxnID   bool
+	defaultUnit   byte
+	protocolID    uint16
+	slaveTimeouts map[byte]time.Duration
+	minInterval   time.Duration
+	maxPDU        int
+	framer        Framer
+	serverIDLen   int // bytes of server ID in Report Server ID responses
+
+	// Exception retry policy
+	exceptionRetries    int
+	exceptionRetryDelay time.Duration
+	retryableExceptions []ExceptionCode
+
+	// retryPolicy, set by NewReliableClient, replaces the single
+	// AutoReconnect retry with reconnects under a backoff policy
+	retryPolicy *RetryPolicy
+
+	// Idle-close state, guarded by mutex
+	idleTimeout time.Duration
+	idleTimer   *time.Timer
+	lastUsed    time.Time
+
+	// lastDone is when the previous exchange finished, guarded by mutex
+	lastDone time.Time
+
+	// Scratch buffers for exchange, guarded by mutex
+	requestBuf []byte
+	headerBuf  [7]byte
+
+	// rmwMutex serializes read-modify-write sequences issued by this client
+	rmwMutex             sync.Mutex
+	maskWriteUnsupported map[byte]bool // slaves that rejected function 0x16
+
+	// Diagnostic state, guarded by statsMutex so it can be inspected
+	// while a request is in flight
+	statsMutex   sync.Mutex
+	lastSlaveID  byte
+	recentSlaves []byte // ring buffer of the last recentSlaveWindow slave IDs
+	recentNext   int
+	errorWindow  int
+	outcomes     map[byte]*outcomeWindow // per function code
+	consecErrors int                     // transport errors since the last success
+	closed       bool
+	idleClosed   bool // the connection was closed by the idle timer
+	counters     ClientStats
+	staleValues  map[staleKey]*staleState
+	listenOnly   map[byte]bool // slaves put into listen-only mode by this client
+	inHook       bool          // OnReconnect is running; suppresses reconnects
+}
+
+// staleKey identifies a register tracked by ReadWithStaleDetection
+type staleKey struct {
+	slaveID byte
+	address uint16
+}
+
+// staleState remembers the last value of a tracked register and how many
+// consecutive reads returned it
+type staleState struct {
+	value uint16
+	count int
+}
+
+// outcomeWindow tracks success/failure of the most recent transactions for a
+// single function code
+type outcomeWindow struct {
+	failed   []bool
+	next     int
+	failures int
 }
 
 // ClientConfig holds configuration for Modbus client
 type ClientConfig struct {
 	Address string        // TCP address (e.g., "192.168.1.100:502")
 	Timeout time.Duration // Operation timeout
+
+	// OnTransaction, if set, is called after every request with the raw
+	// request and response frames (MBAP header plus PDU) and the resulting
+	// error. The response is nil for broadcasts, and nil or partial when the
+	// exchange failed. The hook runs after the connection is released, but it
+	// is still on the caller's path, so keep it fast; it may be called
+	// concurrently when the client is shared between goroutines.
+	OnTransaction func(request, response []byte, err error)
+
+	// ErrorRateWindow is the number of most recent transactions per function
+	// code considered by ErrorRates (default 100)
+	ErrorRateWindow int
+
+	// ProbeAddress is the holding register read by Ping (default 0)
+	ProbeAddress uint16
+
+	// AutoReconnect makes a request that fails with a transport error
+	// reconnect and retry once before reporting the failure
+	AutoReconnect bool
+
+	// OnReconnect, if set, runs after every successful reconnect and before
+	// the retried request, e.g. to repeat a device unlock sequence. It may
+	// issue requests on c; while it runs, failed requests are not
+	// reconnected and retried, so a flapping device cannot recurse into the
+	// hook. Returning an error fails the reconnect.
+	OnReconnect func(c *Client) error
+
+	// TLSConfig, if set, runs the connection over TLS as in the Modbus/TCP
+	// Security profile (usually port 802). Certificate verification, client
+	// certificates and the server name all come from the config.
+	TLSConfig *tls.Config
+
+	// Metrics, if set, observes the duration and result of every request
+	// (default NopMetrics)
+	Metrics Metrics
+
+	// Dialer, if set, opens connections instead of a plain net.Dialer, e.g.
+	// to go through a SOCKS proxy. NewClientWithTransport accepts an already
+	// established connection instead.
+	Dialer ContextDialer
+
+	// Network is the network passed to the dialer (default "tcp"); use
+	// "unix" with a socket path as Address for Unix domain sockets
+	Network string
+
+	// PreferSingleWrites sends single-value WriteMultipleCoils and
+	// WriteMultipleRegisters calls (batches included) as Write Single Coil
+	// (0x05) and Write Single Register (0x06), for devices that implement
+	// only the single-write function codes
+	PreferSingleWrites bool
+
+	// KeepAlivePeriod, if positive, enables TCP keep-alive on the socket
+	// with this probe period so the kernel detects half-open connections.
+	// It applies to the TCP connection beneath TLS too, and is ignored for
+	// connections that aren't TCP.
+	KeepAlivePeriod time.Duration
+
+	// IdleTimeout, if positive, closes the connection once no request has
+	// been made for this long. Later requests fail with ErrIdleClosed, or
+	// reconnect transparently when AutoReconnect is set. It is ignored for
+	// caller-supplied transports, which cannot be redialed.
+	IdleTimeout time.Duration
+
+	// VerifyDelay is how long WriteSingleRegisterVerified waits between the
+	// write and the readback, for devices that take a moment to commit
+	VerifyDelay time.Duration
+
+	// IgnoreTransactionID accepts responses whatever transaction ID they
+	// carry, for non-compliant gateways that always answer with 0. The unit
+	// ID and function code are still checked, but a late response to an
+	// earlier, timed out request can no longer be told apart.
+	IgnoreTransactionID bool
+
+	// DefaultUnitID is the unit ID DefaultDevice addresses (default
+	// TCPUnitID). Gateways need the unit ID of the device behind them.
+	DefaultUnitID byte
+
+	// ProtocolID is written to the MBAP protocol identifier of every request
+	// (default 0, which is Modbus). Only set it for a non-compliant peer that
+	// requires another value.
+	ProtocolID uint16
+
+	// SlaveTimeouts overrides Timeout for requests to the listed slave IDs,
+	// e.g. for a slow device behind a gateway shared with fast ones. Per-call
+	// timeouts still take precedence.
+	SlaveTimeouts map[byte]time.Duration
+
+	// ExceptionRetries is how many times a request answered with a retryable
+	// exception is re-issued before the exception is returned (default 0).
+	// It is independent of AutoReconnect, which only handles transport
+	// errors. Acknowledge (0x05) means the device accepted a long-running
+	// request, so it is re-issued to poll for the result.
+	ExceptionRetries int
+
+	// ExceptionRetryDelay is the wait before each exception retry (default
+	// 100ms). Retries stop early if they would pass a call's deadline, and
+	// cancelling a call's context ends the wait with the context's error.
+	ExceptionRetryDelay time.Duration
+
+	// RetryableExceptions lists the exception codes that are retried
+	// (default Acknowledge and Slave Device Busy)
+	RetryableExceptions []ExceptionCode
+
+	// MinInterval, if positive, is the minimum gap between the end of one
+	// request and the start of the next on this connection, for devices
+	// that lock up when polled too fast. Requests wait for it in turn.
+	MinInterval time.Duration
+
+	// DialRetries is how many more times NewClient tries to connect after
+	// the first attempt fails (default 0), for devices that come online a
+	// little after the application starts
+	DialRetries int
+
+	// DialRetryDelay is the wait before the first dial retry, doubled after
+	// each further failure (default 500ms)
+	DialRetryDelay time.Duration
+
+	// Framer, if set, builds and parses MBAP headers instead of MBAPFramer,
+	// for hardware that doesn't follow the specification
+	Framer Framer
+
+	// MaxPDUSize is the largest PDU the device accepts or sends, in bytes
+	// (default and maximum MaxPDUSize). Chunked helpers size their requests
+	// to fit, and any larger request is rejected before it is sent.
+	MaxPDUSize int
+
+	// ServerIDLength is how many bytes of server ID precede the run
+	// indicator in a Report Server ID response (default 1). The layout is
+	// device specific; ServerID.Raw holds the response data as sent.
+	ServerIDLength int
+}
+
+// ContextDialer opens network connections. *net.Dialer and most proxy
+// dialers implement it.
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
 }
 
 // NewClient creates a new Modbus TCP client
 func NewClient(config ClientConfig) (*Client, error) {
+	return NewClientContext(context.Background(), config)
+}
+
+// NewClientContext is NewClient with a context bounding connection
+// establishment, dial retries included. Cancelling ctx aborts a dial in
+// progress; once the client is returned, ctx no longer affects it.
+func NewClientContext(ctx context.Context, config ClientConfig) (*Client, error) {
+	client := newClient(config)
+
+	conn, err := client.dialContext(ctx)
+	delay := config.DialRetryDelay
+	if delay <= 0 {
+		delay = 500 * time.Millisecond
+	}
+	for retry := 0; err != nil && retry < config.DialRetries && ctx.Err() == nil; retry++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("failed to connect: %w", ctx.Err())
+		case <-timer.C:
+		}
+		delay *= 2
+		conn, err = client.dialContext(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	client.conn = conn
+
+	client.mutex.Lock()
+	client.markActive()
+	client.mutex.Unlock()
+
+	return client, nil
+}
+
+// NewClientWithTransport creates a client that talks over an existing
+// transport, such as a MemoryTransport in tests. Address and TLSConfig are
+// ignored, and Reconnect is not supported since there is nothing to redial.
+func NewClientWithTransport(transport Transport, config ClientConfig) *Client {
+	client := newClient(config)
+	client.conn = transport
+	client.ownTransport = true
+	return client
+}
+
+// newClient applies configuration defaults and builds an unconnected client
+func newClient(config ClientConfig) *Client {
 	if config.Timeout == 0 {
 		config.Timeout = 5 * time.Second
 	}
+	if config.ErrorRateWindow <= 0 {
+		config.ErrorRateWindow = 100
+	}
+	if config.Metrics == nil {
+		config.Metrics = NopMetrics{}
+	}
+	if config.Dialer == nil {
+		config.Dialer = &net.Dialer{}
+	}
+	if config.Network == "" {
+		config.Network = "tcp"
+	}
+	if config.DefaultUnitID == BroadcastSlaveID {
+		config.DefaultUnitID = TCPUnitID
+	}
+	if config.Framer == nil {
+		config.Framer = MBAPFramer{}
+	}
+	if config.MaxPDUSize <= 0 || config.MaxPDUSize > MaxPDUSize {
+		config.MaxPDUSize = MaxPDUSize
+	}
+	if config.ServerIDLength <= 0 {
+		config.ServerIDLength = 1
+	}
+	if config.ExceptionRetryDelay == 0 {
+		config.ExceptionRetryDelay = 100 * time.Millisecond
+	}
+	if len(config.RetryableExceptions) == 0 {
+		config.RetryableExceptions = []ExceptionCode{ExceptionAcknowledge, ExceptionSlaveDeviceBusy}
+	} else {
+		config.RetryableExceptions = append([]ExceptionCode(nil), config.RetryableExceptions...)
+	}
+
+	return &Client{
+		transactionID: randomTransactionID(),
+		address:       config.Address,
+		timeout:       config.Timeout,
+		onTransaction: config.OnTransaction,
+		errorWindow:   config.ErrorRateWindow,
+		probeAddress:  config.ProbeAddress,
+		autoReconnect: config.AutoReconnect,
+		onReconnect:   config.OnReconnect,
+		tlsConfig:     config.TLSConfig,
+		metrics:       config.Metrics,
+		dialer:        config.Dialer,
+		network:       config.Network,
+		preferSingle:  config.PreferSingleWrites,
+		keepAlive:     config.KeepAlivePeriod,
+		verifyDelay:   config.VerifyDelay,
+		idleTimeout:   config.IdleTimeout,
+		ignoreTxnID:   config.IgnoreTransactionID,
+		defaultUnit:   config.DefaultUnitID,
+		protocolID:    config.ProtocolID,
+		slaveTimeouts: copySlaveTimeouts(config.SlaveTimeouts),
+		minInterval:   config.MinInterval,
+		maxPDU:        config.MaxPDUSize,
+		framer:        config.Framer,
+		serverIDLen:   config.ServerIDLength,
+
+		exceptionRetries:    config.ExceptionRetries,
+		exceptionRetryDelay: config.ExceptionRetryDelay,
+		retryableExceptions: config.RetryableExceptions,
+	}
+}
+
+// copySlaveTimeouts copies a per-slave timeout map so later changes by the
+// caller don't race with requests
+func copySlaveTimeouts(timeouts map[byte]time.Duration) map[byte]time.Duration {
+	if len(timeouts) == 0 {
+		return nil
+	}
+	copied := make(map[byte]time.Duration, len(timeouts))
+	for slaveID, timeout := range timeouts {
+		copied[slaveID] = timeout
+	}
+	return copied
+}
 
-	conn, err := net.DialTimeout("tcp", config.Address, config.Timeout)
+// enableKeepAlive turns on TCP keep-alive with the given period. Connections
+// other than *net.TCPConn are left alone.
+func enableKeepAlive(conn net.Conn, period time.Duration) error {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	if err := tcpConn.SetKeepAlive(true); err != nil {
+		return err
+	}
+	return tcpConn.SetKeepAlivePeriod(period)
+}
+
+// randomTransactionID picks the transaction ID a client starts counting
+// from, so a stale response left on a shared gateway is unlikely to match a
+// new client's requests. IDs are kept across reconnects.
+func randomTransactionID() uint16 {
+	var b [2]byte
+	if _, err := cryptorand.Read(b[:]); err != nil {
+		return uint16(time.Now().UnixNano())
+	}
+	return binary.BigEndian.Uint16(b[:])
+}
+
+// dial opens a new connection to the client's address through the configured
+// dialer, over TLS when configured. The timeout covers the TLS handshake.
+func (c *Client) dial() (Transport, error) {
+	return c.dialContext(context.Background())
+}
+
+// dialContext is dial bounded by ctx as well as the client timeout
+func (c *Client) dialContext(parent context.Context) (Transport, error) {
+	ctx, cancel := context.WithTimeout(parent, c.timeout)
+	defer cancel()
+
+	conn, err := c.dialer.DialContext(ctx, c.network, c.address)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
+	if c.keepAlive > 0 {
+		if err := enableKeepAlive(conn, c.keepAlive); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to enable TCP keep-alive: %w", err)
+		}
+	}
+	if c.tlsConfig == nil {
+		return conn, nil
+	}
 
-	return &Client{
-		conn:    conn,
-		timeout: config.Timeout,
-	}, nil
+	// Verify the certificate against the host being dialed unless the
+	// config names a server explicitly, as tls.Dial does
+	config := c.tlsConfig
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(c.address)
+		if err != nil {
+			host = c.address
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	return tlsConn, nil
 }
 
 // Close closes the connection
 func (c *Client) Close() error {
-	return c.conn.Close()
+	c.statsMutex.Lock()
+	c.closed = true
+	conn := c.conn
+	c.statsMutex.Unlock()
+	err := conn.Close()
+
+	// The idle timer is guarded by mutex, so stop it only after closing the
+	// connection has released any request still holding the lock
+	c.mutex.Lock()
+	if c.idleTimer != nil {
+		c.idleTimer.Stop()
+	}
+	c.mutex.Unlock()
+	return err
+}
+
+// Reconnect closes the current connection and dials a new one, keeping the
+// client's transaction ID sequence and statistics. The OnReconnect hook, if
+// configured, runs once the new connection is up; if it fails the new
+// connection is closed again and the hook's error is returned.
+func (c *Client) Reconnect() error {
+	c.mutex.Lock()
+
+	c.statsMutex.Lock()
+	closed := c.closed
+	c.statsMutex.Unlock()
+	if closed {
+		c.mutex.Unlock()
+		return fmt.Errorf("client is closed")
+	}
+	if c.ownTransport {
+		c.mutex.Unlock()
+		return fmt.Errorf("reconnect not supported on a caller-supplied transport")
+	}
+
+	c.conn.Close()
+	conn, err := c.dial()
+	if err == nil {
+		c.statsMutex.Lock()
+		c.conn = conn
+		c.idleClosed = false
+		c.statsMutex.Unlock()
+		c.markActive()
+	}
+	c.mutex.Unlock()
+
+	if err != nil {
+		return &connError{"failed to reconnect", err}
+	}
+
+	// The hook runs without the mutex held so it can issue requests itself
+	if c.onReconnect != nil {
+		c.statsMutex.Lock()
+		c.inHook = true
+		c.statsMutex.Unlock()
+		err := c.onReconnect(c)
+		c.statsMutex.Lock()
+		c.inHook = false
+		c.statsMutex.Unlock()
+
+		if err != nil {
+			conn.Close()
+			return &hookError{err}
+		}
+	}
+
+	return nil
+}
+
+// Flush discards any bytes waiting on the connection, such as the rest of a
+// malformed frame or a late response to a timed out request, so the next
+// request starts on a frame boundary. It returns once nothing has arrived
+// for a short while.
+func (c *Client) Flush() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	buf := make([]byte, 256)
+	for {
+		if err := c.conn.SetReadDeadline(time.Now().Add(flushWait)); err != nil {
+			return err
+		}
+		if _, err := c.conn.Read(buf); err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return nil
+			}
+			return fmt.Errorf("failed to flush connection: %w", err)
+		}
+	}
+}
+
+// Ping checks that the device answers by reading one holding register at the
+// configured probe address. An exception response still proves the device is
+// reachable, so only transport failures are returned.
+func (c *Client) Ping(slaveID byte) error {
+	_, err := c.ReadHoldingRegisters(slaveID, c.probeAddress, 1)
+	if _, isException := err.(*ModbusError); isException {
+		return nil
+	}
+	return err
+}
+
+// Connected reports whether the client is open and its most recent
+// transaction did not fail with a transport error. It does no I/O.
+func (c *Client) Connected() bool {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+	return !c.closed && !c.idleClosed && c.consecErrors == 0
+}
+
+// markActive records use of the connection and restarts the idle timer.
+// The caller must hold mutex.
+func (c *Client) markActive() {
+	if c.idleTimeout <= 0 || c.ownTransport {
+		return
+	}
+	c.statsMutex.Lock()
+	closed := c.closed
+	c.statsMutex.Unlock()
+	if closed {
+		return
+	}
+	c.lastUsed = time.Now()
+	if c.idleTimer == nil {
+		c.idleTimer = time.AfterFunc(c.idleTimeout, c.closeIfIdle)
+	} else {
+		c.idleTimer.Reset(c.idleTimeout)
+	}
+}
+
+// closeIfIdle runs from the idle timer and closes the connection unless it
+// was used again since the timer was armed
+func (c *Client) closeIfIdle() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.statsMutex.Lock()
+	closed := c.closed
+	c.statsMutex.Unlock()
+	if closed {
+		return
+	}
+	if idle := time.Since(c.lastUsed); idle < c.idleTimeout {
+		c.idleTimer.Reset(c.idleTimeout - idle)
+		return
+	}
+
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+	if c.closed || c.idleClosed {
+		return
+	}
+	c.idleClosed = true
+	c.conn.Close()
+}
+
+// SetTimeout changes the per-operation timeout. It takes effect on the next
+// request; a request already in flight keeps its original deadline.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.timeout = d
+}
+
+// LastSlaveID returns the slave ID targeted by the most recent request
+func (c *Client) LastSlaveID() byte {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+	return c.lastSlaveID
+}
+
+// recordSlave remembers the slave ID of a request being issued
+func (c *Client) recordSlave(slaveID byte) {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+
+	c.lastSlaveID = slaveID
+	if len(c.recentSlaves) < recentSlaveWindow {
+		c.recentSlaves = append(c.recentSlaves, slaveID)
+		return
+	}
+	c.recentSlaves[c.recentNext] = slaveID
+	c.recentNext = (c.recentNext + 1) % recentSlaveWindow
+}
+
+// ClientStats holds running totals for a client since it was created
+type ClientStats struct {
+	Requests      uint64                   // Transactions attempted
+	Timeouts      uint64                   // Transactions that hit a deadline
+	Exceptions    map[ExceptionCode]uint64 // Exception responses by code
+	BytesSent     uint64                   // Frame bytes written, MBAP headers included
+	BytesReceived uint64                   // Frame bytes read, MBAP headers included
+}
+
+// Stats returns a snapshot of the client's running totals
+func (c *Client) Stats() ClientStats {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+
+	stats := c.counters
+	stats.Exceptions = make(map[ExceptionCode]uint64, len(c.counters.Exceptions))
+	for code, count := range c.counters.Exceptions {
+		stats.Exceptions[code] = count
+	}
+	return stats
+}
+
+// recordOutcome adds the result of a transaction to the running totals, the
+// error rate window of its function code and the consecutive transport
+// error count. sent and received are the frame bytes transferred.
+func (c *Client) recordOutcome(funcCode byte, sent, received int, err error) {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+
+	c.counters.Requests++
+	c.counters.BytesSent += uint64(sent)
+	c.counters.BytesReceived += uint64(received)
+	if errors.Is(err, ErrTimeout) {
+		c.counters.Timeouts++
+	}
+	if modbusErr, ok := err.(*ModbusError); ok {
+		if c.counters.Exceptions == nil {
+			c.counters.Exceptions = make(map[ExceptionCode]uint64)
+		}
+		c.counters.Exceptions[ExceptionCode(modbusErr.ExceptionCode)]++
+	}
+
+	failed := err != nil
+	if isTransportError(err) {
+		c.consecErrors++
+	} else {
+		c.consecErrors = 0
+	}
+
+	if c.outcomes == nil {
+		c.outcomes = make(map[byte]*outcomeWindow)
+	}
+	window := c.outcomes[funcCode]
+	if window == nil {
+		size := c.errorWindow
+		if size <= 0 {
+			size = 100
+		}
+		window = &outcomeWindow{failed: make([]bool, 0, size)}
+		c.outcomes[funcCode] = window
+	}
+
+	if failed {
+		window.failures++
+	}
+	if len(window.failed) < cap(window.failed) {
+		window.failed = append(window.failed, failed)
+		return
+	}
+	if window.failed[window.next] {
+		window.failures--
+	}
+	window.failed[window.next] = failed
+	window.next = (window.next + 1) % len(window.failed)
+}
+
+// ErrorRates returns, per function code, the fraction of failed transactions
+// (transport errors and exception responses) over the most recent
+// ErrorRateWindow transactions for that code
+func (c *Client) ErrorRates() map[byte]float64 {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+
+	rates := make(map[byte]float64, len(c.outcomes))
+	for funcCode, window := range c.outcomes {
+		rates[funcCode] = float64(window.failures) / float64(len(window.failed))
+	}
+	return rates
+}
+
+// ConsecutiveErrors returns the number of transactions in a row that have
+// failed with transport errors (timeouts, broken connections, bad frames).
+// Modbus exception responses and successes reset the count.
+func (c *Client) ConsecutiveErrors() int {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+	return c.consecErrors
+}
+
+// recentSlaveCounts returns how many of the recent requests targeted each slave ID
+func (c *Client) recentSlaveCounts() map[byte]int {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+
+	counts := make(map[byte]int)
+	for _, id := range c.recentSlaves {
+		counts[id]++
+	}
+	return counts
+}
+
+// requestOptions carries per-call overrides for a single request
+type requestOptions struct {
+	ctx        context.Context // Cancels waiting for MinInterval when set
+	timeout    time.Duration   // Replaces the client timeout when nonzero
+	deadline   time.Time       // Caps the write and read deadlines when nonzero
+	noResponse bool            // Send the request without reading a response
+	buffers    *batchBuffers   // Scratch space reused across a batch when set
+}
+
+// scratchPDU returns the buffer to build the request PDU in, or nil to
+// allocate a fresh one
+func (o requestOptions) scratchPDU() []byte {
+	if o.buffers == nil {
+		return nil
+	}
+	return o.buffers.pdu
 }
 
 // sendRequest sends a Modbus request and returns the response
 func (c *Client) sendRequest(slaveID byte, pdu []byte) ([]byte, error) {
+	return c.sendRequestWith(slaveID, pdu, requestOptions{})
+}
+
+// sendRequestWith is sendRequest with per-call options
+func (c *Client) sendRequestWith(slaveID byte, pdu []byte, opts requestOptions) ([]byte, error) {
+	if len(pdu) > c.maxPDU {
+		return nil, fmt.Errorf("PDU too large: %d bytes exceeds the %d-byte limit", len(pdu), c.maxPDU)
+	}
+	if slaveID == BroadcastSlaveID {
+		if !isWriteFunction(pdu[0]) {
+			return nil, fmt.Errorf("function 0x%02X cannot be broadcast to slave ID 0", pdu[0])
+		}
+		opts.noResponse = true
+	}
+
+	c.recordSlave(slaveID)
+
+	response, err := c.attempt(slaveID, pdu, opts)
+	for retry := 0; retry < c.exceptionRetries && c.isRetryableException(err); retry++ {
+		if !opts.deadline.IsZero() && time.Now().Add(c.exceptionRetryDelay).After(opts.deadline) {
+			break
+		}
+		if !sleepContext(opts.ctx, c.exceptionRetryDelay) {
+			return nil, opts.ctx.Err()
+		}
+		response, err = c.attempt(slaveID, pdu, opts)
+	}
+
+	if err != nil || response == nil {
+		return nil, err
+	}
+
+	return response[7:], nil
+}
+
+// attempt performs one round trip, reconnecting and retrying once on a
+// transport error when AutoReconnect is set, or as the retry policy allows
+// for a ReliableClient
+func (c *Client) attempt(slaveID byte, pdu []byte, opts requestOptions) ([]byte, error) {
+	if c.retryPolicy != nil {
+		return c.attemptWithPolicy(slaveID, pdu, opts)
+	}
+
+	response, err := c.roundTrip(slaveID, pdu, opts)
+	if err != nil && c.autoReconnect && isTransportError(err) && !c.reconnectHookRunning() {
+		if reconnectErr := c.Reconnect(); reconnectErr != nil {
+			return nil, fmt.Errorf("%v; reconnect failed: %w", err, reconnectErr)
+		}
+		response, err = c.roundTrip(slaveID, pdu, opts)
+	}
+	return response, err
+}
+
+// reconnectHookRunning reports whether the OnReconnect hook is running, in
+// which case failed requests must not reconnect again
+func (c *Client) reconnectHookRunning() bool {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+	return c.inHook
+}
+
+// isRetryableException reports whether err is an exception response the
+// client is configured to retry
+func (c *Client) isRetryableException(err error) bool {
+	modbusErr, ok := err.(*ModbusError)
+	if !ok {
+		return false
+	}
+	for _, code := range c.retryableExceptions {
+		if byte(code) == modbusErr.ExceptionCode {
+			return true
+		}
+	}
+	return false
+}
+
+// roundTrip performs one exchange and records its outcome
+func (c *Client) roundTrip(slaveID byte, pdu []byte, opts requestOptions) ([]byte, error) {
+	start := time.Now()
+	request, response, err := c.exchange(slaveID, pdu, opts)
+	c.metrics.ObserveRequest(pdu[0], time.Since(start), err)
+	c.recordOutcome(pdu[0], len(request), len(response), err)
+
+	// Report the exchange after the client mutex has been released so a slow
+	// hook cannot stall other requests on this connection
+	if c.onTransaction != nil {
+		c.onTransaction(request, response, err)
+	}
+
+	return response, err
+}
+
+// isTransportError reports whether err is a failure of the connection
+// itself, which reconnecting may cure. Exception responses, mismatched
+// responses on a working link, local validation errors and cancellation are
+// not.
+func isTransportError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return errors.As(err, new(*connError)) || errors.Is(err, ErrIdleClosed)
+}
+
+// isWriteFunction reports whether a function code only writes data and may
+// therefore be broadcast
+func isWriteFunction(funcCode byte) bool {
+	switch funcCode {
+	case FuncCodeWriteSingleCoil, FuncCodeWriteSingleRegister,
+		FuncCodeWriteMultipleCoils, FuncCodeWriteMultipleRegisters,
+		FuncCodeWriteFileRecord, FuncCodeMaskWriteRegister:
+		return true
+	}
+	return false
+}
+
+// SendRawPDU sends an arbitrary PDU (function code followed by data) to the
+// given slave and returns the raw response PDU. MBAP framing, transaction IDs
+// and exception responses are handled as for the built-in functions, which
+// makes this suitable for vendor-specific function codes.
+func (c *Client) SendRawPDU(slaveID byte, pdu []byte) ([]byte, error) {
+	if len(pdu) == 0 {
+		return nil, fmt.Errorf("empty PDU")
+	}
+	return c.sendRequest(slaveID, pdu)
+}
+
+// exchange performs a single request/response round trip while holding the
+// client mutex. It returns the full request and response frames (MBAP header
+// included) alongside any error; response may be partial or nil on failure.
+func (c *Client) exchange(slaveID byte, pdu []byte, opts requestOptions) ([]byte, []byte, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
+	c.statsMutex.Lock()
+	idleClosed := c.idleClosed
+	c.statsMutex.Unlock()
+	if idleClosed {
+		return nil, nil, ErrIdleClosed
+	}
+	if err := c.waitMinInterval(opts); err != nil {
+		return nil, nil, err
+	}
+	defer func() { c.lastDone = time.Now() }()
+	defer c.markActive()
+
+	timeout := c.timeout
+	if slaveTimeout, ok := c.slaveTimeouts[slaveID]; ok {
+		timeout = slaveTimeout
+	}
+	if opts.timeout > 0 {
+		timeout = opts.timeout
+	}
+	deadline := func() time.Time {
+		d := time.Now().Add(timeout)
+		if !opts.deadline.IsZero() && opts.deadline.Before(d) {
+			return opts.deadline
+		}
+		return d
+	}
+
 	// Increment transaction ID for each request
 	c.transactionID++
 
-	// Build MBAP (Modbus Application Protocol) header
-	mbap := make([]byte, 7)
-	binary.BigEndian.PutUint16(mbap[0:2], c.transactionID)    // Transaction ID
-	binary.BigEndian.PutUint16(mbap[2:4], 0)                  // Protocol ID (0 for Modbus)
-	binary.BigEndian.PutUint16(mbap[4:6], uint16(len(pdu)+1)) // Length
-	mbap[6] = slaveID                                         // Unit ID
+	// Request and header buffers are reused across exchanges unless the
+	// transaction hook needs the frames after the mutex is released
+	reuse := c.onTransaction == nil
+	var request, header []byte
+	if reuse {
+		if cap(c.requestBuf) < 7+len(pdu) {
+			c.requestBuf = make([]byte, 7+len(pdu))
+		}
+		request = c.requestBuf[:7+len(pdu)]
+		header = c.headerBuf[:]
+	} else {
+		request = make([]byte, 7+len(pdu))
+		header = make([]byte, 7)
+	}
 
-	// Combine MBAP header with PDU
-	request := append(mbap, pdu...)
+	// Build MBAP (Modbus Application Protocol) header followed by the PDU
+	c.framer.EncodeHeader(request[:7], c.transactionID, c.protocolID, slaveID, len(pdu))
+	copy(request[7:], pdu)
 
 	// Set write timeout
-	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
-		return nil, err
+	if err := c.conn.SetWriteDeadline(deadline()); err != nil {
+		return request, nil, &connError{"failed to set write deadline", err}
 	}
 
 	// Send request
 	if _, err := c.conn.Write(request); err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return request, nil, &connError{"failed to send request", err}
+	}
+
+	if opts.noResponse {
+		return request, nil, nil
 	}
 
 	// Set read timeout
-	if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
-		return nil, err
+	if err := c.conn.SetReadDeadline(deadline()); err != nil {
+		return request, nil, &connError{"failed to set read deadline", err}
 	}
 
 	// Read response header
-	header := make([]byte, 7)
-	if _, err := c.conn.Read(header); err != nil {
-		return nil, fmt.Errorf("failed to read response header: %w", err)
+	if n, err := io.ReadFull(c.conn, header); err != nil {
+		if n > 0 {
+			return request, header[:n], &connError{fmt.Sprintf("failed to read response header: got %d of %d bytes", n, len(header)), err}
+		}
+		return request, nil, &connError{"failed to read response header", err}
 	}
 
 	// Validate response header
-	respTransactionID := binary.BigEndian.Uint16(header[0:2])
-	if respTransactionID != c.transactionID {
-		return nil, fmt.Errorf("transaction ID mismatch: expected %d, got %d",
-			c.transactionID, respTransactionID)
+	// The rest of a frame with a bad header can't be found on the stream,
+	// so header errors are transport errors that reconnecting clears
+	respTransactionID, respUnitID, dataLength, err := c.framer.DecodeHeader(header)
+	if err != nil {
+		return request, header, &connError{"invalid response header", err}
+	}
+	if dataLength < 0 || dataLength > c.maxPDU {
+		return request, header, &connError{"invalid response header",
+			fmt.Errorf("PDU length %d outside 0-%d", dataLength, c.maxPDU)}
+	}
+	// Read response data directly after the header so the full frame is
+	// available to the transaction hook without another copy
+	var response []byte
+	if reuse && opts.buffers != nil {
+		response = growPDU(opts.buffers.response, 7+dataLength)
+		opts.buffers.response = response
+	} else {
+		response = make([]byte, 7+dataLength)
+	}
+	copy(response, header)
+	data := response[7:]
+	if n, err := io.ReadFull(c.conn, data); err != nil {
+		return request, response[:7+n], &connError{fmt.Sprintf("failed to read response data: got %d of %d bytes", n, len(data)), err}
 	}
 
-	// Read response data
-	dataLength := binary.BigEndian.Uint16(header[4:6]) - 1
-	data := make([]byte, dataLength)
-	if _, err := c.conn.Read(data); err != nil {
-		return nil, fmt.Errorf("failed to read response data: %w", err)
+	// Make sure the response belongs to this request; on a gateway several
+	// unit IDs share the connection. The frame has been read in full, so a
+	// mismatch leaves the stream in sync for the next request.
+	if respTransactionID != c.transactionID && !c.ignoreTxnID {
+		return request, response, fmt.Errorf("transaction ID mismatch: expected %d, got %d",
+			c.transactionID, respTransactionID)
+	}
+	if respUnitID != slaveID {
+		return request, response, fmt.Errorf("unit ID mismatch: expected %d, got %d", slaveID, respUnitID)
+	}
+	if len(data) == 0 {
+		return request, response, fmt.Errorf("empty response PDU")
+	}
+	if data[0]&0x7F != pdu[0] {
+		return request, response, fmt.Errorf("function code mismatch: expected 0x%02X, got 0x%02X",
+			pdu[0], data[0]&0x7F)
 	}
 
 	// Check for exception response
 	if len(data) >= 2 && data[0] >= 0x80 {
-		return nil, &ModbusError{
-			FunctionCode:  data[0] & 0x7F,
-			ExceptionCode: data[1],
-		}
+		return request, response, newModbusError(data)
 	}
 
-	return data, nil
+	return request, response, nil
+}
+
+// waitMinInterval sleeps until MinInterval has passed since the previous
+// exchange. It gives up early if opts' context ends, or fails at once if the
+// wait would pass opts' deadline. The caller must hold mutex.
+func (c *Client) waitMinInterval(opts requestOptions) error {
+	if c.minInterval <= 0 || c.lastDone.IsZero() {
+		return nil
+	}
+	wait := time.Until(c.lastDone.Add(c.minInterval))
+	if wait <= 0 {
+		return nil
+	}
+	if !opts.deadline.IsZero() && time.Now().Add(wait).After(opts.deadline) {
+		return fmt.Errorf("%w: minimum request interval would pass the deadline", ErrTimeout)
+	}
+
+	var done <-chan struct{}
+	if opts.ctx != nil {
+		done = opts.ctx.Done()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return opts.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// checkAddressRange verifies that quantity items starting at address fit in
+// the 16-bit address space, catching address arithmetic mistakes before the
+// request reaches the device. A failure is an *AddressRangeError.
+func checkAddressRange(address uint16, quantity int) error {
+	if int(address)+quantity > 0x10000 {
+		return &AddressRangeError{Address: address, Quantity: quantity}
+	}
+	return nil
 }
 
 // ReadCoils reads coil status (function code 0x01)
 func (c *Client) ReadCoils(slaveID byte, address, quantity uint16) ([]bool, error) {
+	response, err := c.readBitsResponse(slaveID, FuncCodeReadCoils, address, quantity, requestOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	coils := make([]bool, quantity)
+	decodeCoils(response, coils)
+	return coils, nil
+}
+
+// ReadCoilsInto reads quantity coils into dst without allocating a result
+// slice, returning the number of coils stored. dst must hold at least
+// quantity values.
+func (c *Client) ReadCoilsInto(slaveID byte, address, quantity uint16, dst []bool) (int, error) {
+	if len(dst) < int(quantity) {
+		return 0, fmt.Errorf("destination too small: %d values for quantity %d", len(dst), quantity)
+	}
+
+	response, err := c.readBitsResponse(slaveID, FuncCodeReadCoils, address, quantity, requestOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	decodeCoils(response, dst[:quantity])
+	return int(quantity), nil
+}
+
+// ReadDiscreteInputs reads discrete input status (function code 0x02)
+func (c *Client) ReadDiscreteInputs(slaveID byte, address, quantity uint16) ([]bool, error) {
+	response, err := c.readBitsResponse(slaveID, FuncCodeReadDiscreteInputs, address, quantity, requestOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]bool, quantity)
+	decodeCoils(response, inputs)
+	return inputs, nil
+}
+
+// readBitsResponse sends a Read Coils or Read Discrete Inputs request and
+// returns the validated response PDU
+func (c *Client) readBitsResponse(slaveID, funcCode byte, address, quantity uint16, opts requestOptions) ([]byte, error) {
 	if quantity == 0 || quantity > 2000 {
 		return nil, fmt.Errorf("invalid quantity: %d (must be 1-2000)", quantity)
 	}
+	if err := checkAddressRange(address, int(quantity)); err != nil {
+		return nil, err
+	}
 
-	// Build PDU
-	pdu := make([]byte, 5)
-	pdu[0] = FuncCodeReadCoils
-	binary.BigEndian.PutUint16(pdu[1:3], address)
-	binary.BigEndian.PutUint16(pdu[3:5], quantity)
-
-	response, err := c.sendRequest(slaveID, pdu)
+	response, err := c.sendRequestWith(slaveID, readRequestPDU(opts.scratchPDU(), funcCode, address, quantity), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -162,70 +1428,106 @@ func (c *Client) ReadCoils(slaveID byte, address, quantity uint16) ([]bool, erro
 	}
 
 	byteCount := response[1]
-	if len(response) != int(2+byteCount) {
+	if byteCount != byte((quantity+7)/8) || len(response) != int(2+byteCount) {
 		return nil, fmt.Errorf("response length mismatch")
 	}
 
-	// Convert bytes to boolean array
-	coils := make([]bool, quantity)
-	for i := uint16(0); i < quantity; i++ {
+	return response, nil
+}
+
+// decodeCoils unpacks the coil bits of a read response into coils
+func decodeCoils(response []byte, coils []bool) {
+	for i := range coils {
 		byteIndex := i / 8
 		bitIndex := i % 8
 		coils[i] = (response[2+byteIndex] & (1 << bitIndex)) != 0
 	}
-
-	return coils, nil
 }
 
 // ReadHoldingRegisters reads holding registers (function code 0x03)
 func (c *Client) ReadHoldingRegisters(slaveID byte, address, quantity uint16) ([]uint16, error) {
-	if quantity == 0 || quantity > 125 {
-		return nil, fmt.Errorf("invalid quantity: %d (must be 1-125)", quantity)
-	}
+	return c.readRegisters(slaveID, FuncCodeReadHoldingRegisters, address, quantity, requestOptions{})
+}
 
-	// Build PDU
-	pdu := make([]byte, 5)
-	pdu[0] = FuncCodeReadHoldingRegisters
-	binary.BigEndian.PutUint16(pdu[1:3], address)
-	binary.BigEndian.PutUint16(pdu[3:5], quantity)
+// ReadHoldingRegistersTimeout reads holding registers using timeout instead
+// of the client timeout for this call only
+func (c *Client) ReadHoldingRegistersTimeout(slaveID byte, address, quantity uint16, timeout time.Duration) ([]uint16, error) {
+	return c.readRegisters(slaveID, FuncCodeReadHoldingRegisters, address, quantity, requestOptions{timeout: timeout})
+}
 
-	response, err := c.sendRequest(slaveID, pdu)
+// ReadInputRegisters reads input registers (function code 0x04)
+func (c *Client) ReadInputRegisters(slaveID byte, address, quantity uint16) ([]uint16, error) {
+	return c.readRegisters(slaveID, FuncCodeReadInputRegisters, address, quantity, requestOptions{})
+}
+
+// ReadInputRegistersTimeout reads input registers using timeout instead of
+// the client timeout for this call only
+func (c *Client) ReadInputRegistersTimeout(slaveID byte, address, quantity uint16, timeout time.Duration) ([]uint16, error) {
+	return c.readRegisters(slaveID, FuncCodeReadInputRegisters, address, quantity, requestOptions{timeout: timeout})
+}
+
+// ReadHoldingRegistersInto reads quantity holding registers into dst without
+// allocating a result slice, returning the number of registers stored. dst
+// must hold at least quantity values.
+func (c *Client) ReadHoldingRegistersInto(slaveID byte, address, quantity uint16, dst []uint16) (int, error) {
+	return c.readRegistersInto(slaveID, FuncCodeReadHoldingRegisters, address, quantity, dst)
+}
+
+// ReadInputRegistersInto is ReadHoldingRegistersInto for input registers
+func (c *Client) ReadInputRegistersInto(slaveID byte, address, quantity uint16, dst []uint16) (int, error) {
+	return c.readRegistersInto(slaveID, FuncCodeReadInputRegisters, address, quantity, dst)
+}
+
+// ReadRawBytes reads holding registers and returns their payload exactly as
+// sent on the wire: quantity*2 bytes, big-endian within each register
+func (c *Client) ReadRawBytes(slaveID byte, address, quantity uint16) ([]byte, error) {
+	response, err := c.readRegistersResponse(slaveID, FuncCodeReadHoldingRegisters, address, quantity, requestOptions{})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(response) < 2 {
-		return nil, fmt.Errorf("invalid response length")
-	}
+	return append([]byte(nil), response[2:]...), nil
+}
 
-	byteCount := response[1]
-	expectedLength := quantity * 2
-	if byteCount != byte(expectedLength) || len(response) != int(2+byteCount) {
-		return nil, fmt.Errorf("response length mismatch")
+// readRegisters implements the holding and input register reads
+func (c *Client) readRegisters(slaveID, funcCode byte, address, quantity uint16, opts requestOptions) ([]uint16, error) {
+	response, err := c.readRegistersResponse(slaveID, funcCode, address, quantity, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	// Convert bytes to uint16 array
 	registers := make([]uint16, quantity)
-	for i := uint16(0); i < quantity; i++ {
-		registers[i] = binary.BigEndian.Uint16(response[2+i*2 : 4+i*2])
+	decodeRegisters(response, registers)
+	return registers, nil
+}
+
+// readRegistersInto implements the holding and input register reads into a
+// caller-supplied slice
+func (c *Client) readRegistersInto(slaveID, funcCode byte, address, quantity uint16, dst []uint16) (int, error) {
+	if len(dst) < int(quantity) {
+		return 0, fmt.Errorf("destination too small: %d values for quantity %d", len(dst), quantity)
 	}
 
-	return registers, nil
+	response, err := c.readRegistersResponse(slaveID, funcCode, address, quantity, requestOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	decodeRegisters(response, dst[:quantity])
+	return int(quantity), nil
 }
 
-// ReadInputRegisters reads input registers (function code 0x04)
-func (c *Client) ReadInputRegisters(slaveID byte, address, quantity uint16) ([]uint16, error) {
+// readRegistersResponse sends a register read request and returns the
+// validated response PDU
+func (c *Client) readRegistersResponse(slaveID, funcCode byte, address, quantity uint16, opts requestOptions) ([]byte, error) {
 	if quantity == 0 || quantity > 125 {
 		return nil, fmt.Errorf("invalid quantity: %d (must be 1-125)", quantity)
 	}
+	if err := checkAddressRange(address, int(quantity)); err != nil {
+		return nil, err
+	}
 
-	// Build PDU
-	pdu := make([]byte, 5)
-	pdu[0] = FuncCodeReadInputRegisters
-	binary.BigEndian.PutUint16(pdu[1:3], address)
-	binary.BigEndian.PutUint16(pdu[3:5], quantity)
-
-	response, err := c.sendRequest(slaveID, pdu)
+	response, err := c.sendRequestWith(slaveID, readRequestPDU(opts.scratchPDU(), funcCode, address, quantity), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -240,29 +1542,20 @@ func (c *Client) ReadInputRegisters(slaveID byte, address, quantity uint16) ([]u
 		return nil, fmt.Errorf("response length mismatch")
 	}
 
-	// Convert bytes to uint16 array
-	registers := make([]uint16, quantity)
-	for i := uint16(0); i < quantity; i++ {
+	return response, nil
+}
+
+// decodeRegisters converts the register words of a read response into registers
+func decodeRegisters(response []byte, registers []uint16) {
+	for i := range registers {
 		registers[i] = binary.BigEndian.Uint16(response[2+i*2 : 4+i*2])
 	}
-
-	return registers, nil
 }
 
 // WriteSingleCoil writes a single coil (function code 0x05)
 func (c *Client) WriteSingleCoil(slaveID byte, address uint16, value bool) error {
-	// Build PDU
-	pdu := make([]byte, 5)
-	pdu[0] = FuncCodeWriteSingleCoil
-	binary.BigEndian.PutUint16(pdu[1:3], address)
-	if value {
-		binary.BigEndian.PutUint16(pdu[3:5], 0xFF00)
-	} else {
-		binary.BigEndian.PutUint16(pdu[3:5], 0x0000)
-	}
-
-	response, err := c.sendRequest(slaveID, pdu)
-	if err != nil {
+	response, err := c.sendRequest(slaveID, writeSingleCoilPDU(address, value))
+	if err != nil || slaveID == BroadcastSlaveID {
 		return err
 	}
 
@@ -270,20 +1563,23 @@ func (c *Client) WriteSingleCoil(slaveID byte, address uint16, value bool) error
 	if len(response) != 5 || response[0] != FuncCodeWriteSingleCoil {
 		return fmt.Errorf("invalid response")
 	}
+	if binary.BigEndian.Uint16(response[1:3]) != address {
+		return fmt.Errorf("response address mismatch")
+	}
+
+	// Some devices echo 0x0001 instead of 0xFF00 for a successful "on" write,
+	// so any nonzero echo is accepted as on
+	if echoed := binary.BigEndian.Uint16(response[3:5]) != 0; echoed != value {
+		return fmt.Errorf("response value mismatch")
+	}
 
 	return nil
 }
 
 // WriteSingleRegister writes a single register (function code 0x06)
 func (c *Client) WriteSingleRegister(slaveID byte, address, value uint16) error {
-	// Build PDU
-	pdu := make([]byte, 5)
-	pdu[0] = FuncCodeWriteSingleRegister
-	binary.BigEndian.PutUint16(pdu[1:3], address)
-	binary.BigEndian.PutUint16(pdu[3:5], value)
-
-	response, err := c.sendRequest(slaveID, pdu)
-	if err != nil {
+	response, err := c.sendRequest(slaveID, writeSingleRegisterPDU(address, value))
+	if err != nil || slaveID == BroadcastSlaveID {
 		return err
 	}
 
@@ -295,34 +1591,93 @@ func (c *Client) WriteSingleRegister(slaveID byte, address, value uint16) error
 	return nil
 }
 
+// WriteSingleRegisterVerified writes a single register, then reads it back
+// and fails if the device stored a different value, e.g. because it clamped
+// the setpoint. The readback waits for VerifyDelay first.
+func (c *Client) WriteSingleRegisterVerified(slaveID byte, address, value uint16) error {
+	if slaveID == BroadcastSlaveID {
+		return fmt.Errorf("cannot verify a broadcast write")
+	}
+	if err := c.WriteSingleRegister(slaveID, address, value); err != nil {
+		return err
+	}
+
+	if c.verifyDelay > 0 {
+		time.Sleep(c.verifyDelay)
+	}
+
+	registers, err := c.ReadHoldingRegisters(slaveID, address, 1)
+	if err != nil {
+		return fmt.Errorf("readback failed: %w", err)
+	}
+	if registers[0] != value {
+		return fmt.Errorf("readback mismatch at register %d: wrote %d, read %d", address, value, registers[0])
+	}
+
+	return nil
+}
+
 // WriteMultipleCoils writes multiple coils (function code 0x0F)
 func (c *Client) WriteMultipleCoils(slaveID byte, address uint16, values []bool) error {
-	quantity := uint16(len(values))
-	if quantity == 0 || quantity > 1968 {
-		return fmt.Errorf("invalid quantity: %d (must be 1-1968)", quantity)
+	return c.writeMultipleCoils(slaveID, address, values, requestOptions{})
+}
+
+// writeMultipleCoils applies the single-coil shortcut before writeCoils. The
+// shortcut always goes through WriteSingleCoil, so opts only reach the
+// multiple-coil request.
+func (c *Client) writeMultipleCoils(slaveID byte, address uint16, values []bool, opts requestOptions) error {
+	return c.preferSingleWrite(len(values),
+		func() error { return c.WriteSingleCoil(slaveID, address, values[0]) },
+		func() error { return c.writeCoils(slaveID, address, values, opts) })
+}
+
+// preferSingleWrite calls single instead of multiple when PreferSingleWrites
+// is set and the write covers exactly one value
+func (c *Client) preferSingleWrite(count int, single, multiple func() error) error {
+	if c.preferSingle && count == 1 {
+		return single()
 	}
+	return multiple()
+}
 
-	// Calculate byte count
-	byteCount := (quantity + 7) / 8
+// writeCoils implements WriteMultipleCoils without the single-coil shortcut
+func (c *Client) writeCoils(slaveID byte, address uint16, values []bool, opts requestOptions) error {
+	if len(values) == 0 || len(values) > 1968 {
+		return fmt.Errorf("invalid quantity: %d (must be 1-1968)", len(values))
+	}
+	quantity := uint16(len(values))
+	if err := checkAddressRange(address, len(values)); err != nil {
+		return err
+	}
 
-	// Build PDU
-	pdu := make([]byte, 6+byteCount)
-	pdu[0] = FuncCodeWriteMultipleCoils
-	binary.BigEndian.PutUint16(pdu[1:3], address)
-	binary.BigEndian.PutUint16(pdu[3:5], quantity)
-	pdu[5] = byte(byteCount)
+	pdu := writeMultipleCoilsPDU(opts.scratchPDU(), address, values)
+	return c.writeCoilsPDU(slaveID, address, quantity, pdu, opts)
+}
 
-	// Convert boolean array to bytes
-	for i, value := range values {
-		if value {
-			byteIndex := i / 8
-			bitIndex := i % 8
-			pdu[6+byteIndex] |= 1 << bitIndex
-		}
+// WriteMultipleCoilsBytes writes quantity coils (function code 0x0F) from
+// bytes already packed as they go on the wire: coil address first in the
+// least significant bit. Unlike WriteMultipleCoils, which always clears the
+// unused high bits of the last byte, the padding bits are sent as given, for
+// devices that insist on a particular value. packed must hold exactly
+// (quantity+7)/8 bytes.
+func (c *Client) WriteMultipleCoilsBytes(slaveID byte, address, quantity uint16, packed []byte) error {
+	if quantity == 0 || quantity > 1968 {
+		return fmt.Errorf("invalid quantity: %d (must be 1-1968)", quantity)
+	}
+	if len(packed) != int(quantity+7)/8 {
+		return fmt.Errorf("packed length mismatch: %d bytes for %d coils (want %d)", len(packed), quantity, (quantity+7)/8)
+	}
+	if err := checkAddressRange(address, int(quantity)); err != nil {
+		return err
 	}
 
-	response, err := c.sendRequest(slaveID, pdu)
-	if err != nil {
+	return c.writeCoilsPDU(slaveID, address, quantity, packedCoilsPDU(address, quantity, packed), requestOptions{})
+}
+
+// writeCoilsPDU sends a Write Multiple Coils PDU and verifies the echoed range
+func (c *Client) writeCoilsPDU(slaveID byte, address, quantity uint16, pdu []byte, opts requestOptions) error {
+	response, err := c.sendRequestWith(slaveID, pdu, opts)
+	if err != nil || slaveID == BroadcastSlaveID {
 		return err
 	}
 
@@ -331,105 +1686,648 @@ func (c *Client) WriteMultipleCoils(slaveID byte, address uint16, values []bool)
 		return fmt.Errorf("invalid response")
 	}
 
-	return nil
+	return verifyWriteRange(response, address, quantity)
 }
 
 // WriteMultipleRegisters writes multiple registers (function code 0x10)
 func (c *Client) WriteMultipleRegisters(slaveID byte, address uint16, values []uint16) error {
+	return c.writeMultipleRegisters(slaveID, address, values, requestOptions{})
+}
+
+// writeMultipleRegisters applies the single-register shortcut before
+// writeRegisters, as writeMultipleCoils does for coils
+func (c *Client) writeMultipleRegisters(slaveID byte, address uint16, values []uint16, opts requestOptions) error {
+	return c.preferSingleWrite(len(values),
+		func() error { return c.WriteSingleRegister(slaveID, address, values[0]) },
+		func() error { return c.writeRegisters(slaveID, address, values, opts) })
+}
+
+// writeRegisters implements WriteMultipleRegisters without the
+// single-register shortcut
+func (c *Client) writeRegisters(slaveID byte, address uint16, values []uint16, opts requestOptions) error {
+	if len(values) == 0 || len(values) > 123 {
+		return fmt.Errorf("invalid quantity: %d (must be 1-123)", len(values))
+	}
 	quantity := uint16(len(values))
-	if quantity == 0 || quantity > 123 {
-		return fmt.Errorf("invalid quantity: %d (must be 1-123)", quantity)
+	if err := checkAddressRange(address, len(values)); err != nil {
+		return err
+	}
+
+	response, err := c.sendRequestWith(slaveID, writeMultipleRegistersPDU(opts.scratchPDU(), address, values), opts)
+	if err != nil || slaveID == BroadcastSlaveID {
+		return err
+	}
+
+	// Verify response
+	if len(response) != 5 || response[0] != FuncCodeWriteMultipleRegisters {
+		return fmt.Errorf("invalid response")
+	}
+
+	return verifyWriteRange(response, address, quantity)
+}
+
+// verifyWriteRange checks that a multiple-write response acknowledges the
+// requested starting address and quantity
+func verifyWriteRange(response []byte, address, quantity uint16) error {
+	respAddress := binary.BigEndian.Uint16(response[1:3])
+	respQuantity := binary.BigEndian.Uint16(response[3:5])
+	if respAddress != address || respQuantity != quantity {
+		return fmt.Errorf("write acknowledged for address %d quantity %d, expected address %d quantity %d",
+			respAddress, respQuantity, address, quantity)
 	}
+	return nil
+}
 
-	byteCount := quantity * 2
+// WriteMultipleRegistersBytes writes data to consecutive holding registers,
+// two bytes per register with the high byte first, as ReadRawBytes returns
+// them. data must have an even length.
+func (c *Client) WriteMultipleRegistersBytes(slaveID byte, address uint16, data []byte) error {
+	if len(data)%2 != 0 {
+		return fmt.Errorf("odd data length: %d bytes (must be whole registers)", len(data))
+	}
+	return c.WriteMultipleRegisters(slaveID, address, bytesToRegisters(data, false))
+}
 
+// MaskWriteRegister modifies a holding register using AND and OR masks
+// (function code 0x16). The device computes
+// (current AND andMask) OR (orMask AND NOT andMask).
+func (c *Client) MaskWriteRegister(slaveID byte, address, andMask, orMask uint16) error {
 	// Build PDU
-	pdu := make([]byte, 6+byteCount)
-	pdu[0] = FuncCodeWriteMultipleRegisters
+	pdu := make([]byte, 7)
+	pdu[0] = FuncCodeMaskWriteRegister
 	binary.BigEndian.PutUint16(pdu[1:3], address)
-	binary.BigEndian.PutUint16(pdu[3:5], quantity)
-	pdu[5] = byte(byteCount)
+	binary.BigEndian.PutUint16(pdu[3:5], andMask)
+	binary.BigEndian.PutUint16(pdu[5:7], orMask)
 
-	// Convert uint16 array to bytes
-	for i, value := range values {
-		binary.BigEndian.PutUint16(pdu[6+i*2:8+i*2], value)
+	response, err := c.sendRequest(slaveID, pdu)
+	if err != nil || slaveID == BroadcastSlaveID {
+		return err
 	}
 
-	response, err := c.sendRequest(slaveID, pdu)
+	// Verify echo response
+	if len(response) != 7 || string(response) != string(pdu) {
+		return fmt.Errorf("invalid response")
+	}
+
+	return nil
+}
+
+// ReadRegisterBit reads a single bit (0-15) of a holding register
+func (c *Client) ReadRegisterBit(slaveID byte, address uint16, bit int) (bool, error) {
+	if bit < 0 || bit > 15 {
+		return false, fmt.Errorf("invalid bit: %d (must be 0-15)", bit)
+	}
+
+	registers, err := c.ReadHoldingRegisters(slaveID, address, 1)
+	if err != nil {
+		return false, err
+	}
+
+	return registers[0]&(1<<uint(bit)) != 0, nil
+}
+
+// WriteRegisterBit sets or clears a single bit (0-15) of a holding register,
+// leaving the other bits untouched. Mask Write Register (0x16) is used when
+// the device supports it; otherwise the register is read, modified and
+// written back while other read-modify-write helpers on this client are held off.
+func (c *Client) WriteRegisterBit(slaveID byte, address uint16, bit int, value bool) error {
+	if bit < 0 || bit > 15 {
+		return fmt.Errorf("invalid bit: %d (must be 0-15)", bit)
+	}
+
+	mask := uint16(1) << uint(bit)
+	var bits uint16
+	if value {
+		bits = mask
+	}
+
+	return c.writeMasked(slaveID, address, mask, bits)
+}
+
+// bitFieldMask validates a bit field and returns its mask, unshifted
+func bitFieldMask(startBit, bitWidth uint8) (uint16, error) {
+	if bitWidth == 0 || int(startBit)+int(bitWidth) > 16 {
+		return 0, fmt.Errorf("invalid bit field: start %d width %d (must fit in bits 0-15)", startBit, bitWidth)
+	}
+	return uint16(1<<bitWidth - 1), nil
+}
+
+// ReadBitField reads a holding register and extracts the bitWidth-bit field
+// starting at startBit, shifted down to bit 0
+func (c *Client) ReadBitField(slaveID byte, address uint16, startBit, bitWidth uint8) (uint16, error) {
+	mask, err := bitFieldMask(startBit, bitWidth)
+	if err != nil {
+		return 0, err
+	}
+
+	registers, err := c.ReadHoldingRegisters(slaveID, address, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return registers[0] >> startBit & mask, nil
+}
+
+// WriteBitField replaces the bitWidth-bit field starting at startBit in a
+// holding register with value, leaving the other bits untouched. Like
+// WriteRegisterBit it prefers Mask Write Register (0x16).
+func (c *Client) WriteBitField(slaveID byte, address uint16, startBit, bitWidth uint8, value uint16) error {
+	mask, err := bitFieldMask(startBit, bitWidth)
+	if err != nil {
+		return err
+	}
+	if value > mask {
+		return fmt.Errorf("bit field value %d does not fit in %d bits", value, bitWidth)
+	}
+
+	return c.writeMasked(slaveID, address, mask<<startBit, value<<startBit)
+}
+
+// writeMasked replaces the bits selected by mask in a holding register with
+// the corresponding bits of value, preferring a device-side mask write
+func (c *Client) writeMasked(slaveID byte, address, mask, value uint16) error {
+	c.rmwMutex.Lock()
+	defer c.rmwMutex.Unlock()
+
+	// Behind a gateway each slave is its own device, so support for mask
+	// writes is remembered per slave
+	if !c.maskWriteUnsupported[slaveID] {
+		err := c.MaskWriteRegister(slaveID, address, ^mask, value&mask)
+		modbusErr, ok := err.(*ModbusError)
+		if !ok || modbusErr.ExceptionCode != ExceptionIllegalFunction {
+			return err
+		}
+		if c.maskWriteUnsupported == nil {
+			c.maskWriteUnsupported = make(map[byte]bool)
+		}
+		c.maskWriteUnsupported[slaveID] = true
+	}
+
+	registers, err := c.ReadHoldingRegisters(slaveID, address, 1)
 	if err != nil {
 		return err
 	}
 
-	// Verify response
-	if len(response) != 5 || response[0] != FuncCodeWriteMultipleRegisters {
-		return fmt.Errorf("invalid response")
+	return c.WriteSingleRegister(slaveID, address, registers[0]&^mask|value&mask)
+}
+
+// ReadEventQueue reads a circular event queue of state codes kept by the
+// device in holding registers. The register at headAddr holds the index of
+// the slot that will be written next, which is the oldest entry of a full
+// queue; the bufLen registers starting at bufAddr hold the codes. Events are
+// returned oldest first, each translated to a label by decode (or rendered
+// as hex when decode is nil).
+func (c *Client) ReadEventQueue(slaveID byte, headAddr, bufAddr uint16, bufLen int, decode func(uint16) string) ([]string, error) {
+	if bufLen <= 0 || bufLen > 125 {
+		return nil, fmt.Errorf("invalid buffer length: %d (must be 1-125)", bufLen)
+	}
+
+	head, err := c.ReadHoldingRegisters(slaveID, headAddr, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue head: %w", err)
+	}
+	if int(head[0]) >= bufLen {
+		return nil, fmt.Errorf("queue head %d out of range for buffer length %d", head[0], bufLen)
+	}
+
+	codes, err := c.ReadHoldingRegisters(slaveID, bufAddr, uint16(bufLen))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue buffer: %w", err)
+	}
+
+	events := make([]string, bufLen)
+	for i := range events {
+		code := codes[(int(head[0])+i)%bufLen]
+		if decode != nil {
+			events[i] = decode(code)
+		} else {
+			events[i] = fmt.Sprintf("0x%04X", code)
+		}
+	}
+
+	return events, nil
+}
+
+// ReadExceptionStatus reads the device's eight exception status outputs
+// (function code 0x07), returned as a bit field
+func (c *Client) ReadExceptionStatus(slaveID byte) (byte, error) {
+	return c.readExceptionStatus(slaveID, requestOptions{})
+}
+
+// readExceptionStatus is ReadExceptionStatus with per-call options
+func (c *Client) readExceptionStatus(slaveID byte, opts requestOptions) (byte, error) {
+	response, err := c.sendRequestWith(slaveID, []byte{FuncCodeReadExceptionStatus}, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(response) != 2 {
+		return 0, fmt.Errorf("invalid response length")
+	}
+
+	return response[1], nil
+}
+
+// ReadFIFOQueue reads the contents of a first-in-first-out queue of registers
+// (function code 0x18). The device returns up to 31 queued values, read from
+// the queue whose count register is at fifoPointerAddress.
+func (c *Client) ReadFIFOQueue(slaveID byte, fifoPointerAddress uint16) ([]uint16, error) {
+	// Build PDU
+	pdu := make([]byte, 3)
+	pdu[0] = FuncCodeReadFIFOQueue
+	binary.BigEndian.PutUint16(pdu[1:3], fifoPointerAddress)
+
+	response, err := c.sendRequest(slaveID, pdu)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response) < 5 {
+		return nil, fmt.Errorf("invalid response length")
+	}
+
+	byteCount := int(binary.BigEndian.Uint16(response[1:3]))
+	fifoCount := int(binary.BigEndian.Uint16(response[3:5]))
+	if fifoCount > maxFIFOCount {
+		return nil, fmt.Errorf("invalid FIFO count: %d (max %d)", fifoCount, maxFIFOCount)
+	}
+	if byteCount != 2+2*fifoCount || len(response) != 3+byteCount {
+		return nil, fmt.Errorf("response length mismatch")
+	}
+
+	values := make([]uint16, fifoCount)
+	for i := range values {
+		values[i] = binary.BigEndian.Uint16(response[5+i*2 : 7+i*2])
+	}
+
+	return values, nil
+}
+
+// ReadWithStaleDetection reads one holding register and reports it as stale
+// once more than staleThreshold consecutive calls for the same slave and
+// address have returned an identical value, which may indicate a frozen
+// sensor. Any change in value resets the count; failed reads leave it as is.
+func (c *Client) ReadWithStaleDetection(slaveID byte, address uint16, staleThreshold int) (uint16, bool, error) {
+	registers, err := c.ReadHoldingRegisters(slaveID, address, 1)
+	if err != nil {
+		return 0, false, err
+	}
+	value := registers[0]
+
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+
+	if c.staleValues == nil {
+		c.staleValues = make(map[staleKey]*staleState)
+	}
+	key := staleKey{slaveID: slaveID, address: address}
+	state := c.staleValues[key]
+	if state == nil || state.value != value {
+		state = &staleState{value: value}
+		c.staleValues[key] = state
+	}
+	state.count++
+
+	return value, state.count > staleThreshold, nil
+}
+
+// WriteStep is a single register write within a WriteTransaction
+type WriteStep struct {
+	Address uint16   // Starting register address
+	Values  []uint16 // Register values to write
+}
+
+// TransactionError is returned by WriteTransaction when one of its writes fails
+type TransactionError struct {
+	Step        int   // Index of the write that failed
+	Err         error // Error returned by the failed write
+	RollbackErr error // First error encountered while restoring original values, if any
+}
+
+func (e *TransactionError) Error() string {
+	if e.RollbackErr != nil {
+		return fmt.Sprintf("write transaction failed at step %d: %v (rollback failed: %v)",
+			e.Step, e.Err, e.RollbackErr)
+	}
+	return fmt.Sprintf("write transaction failed at step %d: %v (rolled back)", e.Step, e.Err)
+}
+
+func (e *TransactionError) Unwrap() error {
+	return e.Err
+}
+
+// WriteTransaction applies a group of register writes with all-or-nothing semantics.
+// The current values of every target range are read first; if any write then
+// fails, the ranges already written are restored in reverse order and a
+// *TransactionError describing both the failure and any rollback problem is returned.
+// Other clients may still observe the intermediate state while the group is applied.
+func (c *Client) WriteTransaction(slaveID byte, writes []WriteStep) error {
+	originals := make([][]uint16, len(writes))
+	for i, step := range writes {
+		values, err := c.ReadHoldingRegisters(slaveID, step.Address, uint16(len(step.Values)))
+		if err != nil {
+			return fmt.Errorf("failed to read original values for step %d: %w", i, err)
+		}
+		originals[i] = values
+	}
+
+	for i, step := range writes {
+		if err := c.WriteMultipleRegisters(slaveID, step.Address, step.Values); err != nil {
+			txErr := &TransactionError{Step: i, Err: err}
+			for j := i - 1; j >= 0; j-- {
+				rollbackErr := c.WriteMultipleRegisters(slaveID, writes[j].Address, originals[j])
+				if rollbackErr != nil && txErr.RollbackErr == nil {
+					txErr.RollbackErr = fmt.Errorf("step %d: %w", j, rollbackErr)
+				}
+			}
+			return txErr
+		}
 	}
 
 	return nil
 }
 
-// BatchOperation represents a batch operation
+// BatchOperation represents a batch operation. ExecuteBatch merges a run of
+// reads with the same operation and slave ID into one request when each
+// starts where the one before it ends and the run fits in a single read;
+// every other operation is sent as its own request.
 type BatchOperation struct {
-	Operation string      // "read_coils", "read_holding", "read_input", "write_coils", "write_registers"
+	Operation string      // "read_coils", "read_discrete", "read_holding", "read_input", "write_coils", "write_registers", "read_float32", "write_float32"
 	SlaveID   byte        // Slave ID
 	Address   uint16      // Starting address
-	Values    interface{} // Values for write operations
-	Quantity  uint16      // Quantity for read operations
+	Values    interface{} // Values for write operations ([]float32 for write_float32)
+	Quantity  uint16      // Quantity for read operations (number of floats for read_float32)
+	ByteOrder ByteOrder   // Word order for float operations (default DefaultByteOrder)
 }
 
 // BatchResult represents the result of a batch operation
 type BatchResult struct {
 	Operation string      // Operation type
-	Values    interface{} // Result values ([]bool for coils, []uint16 for registers)
+	Values    interface{} // Result values ([]bool for coils and discrete inputs, []uint16 for registers, []float32 for read_float32)
 	Error     error       // Error if operation failed
+
+	// Duration is how long the operation's request took on the wire, from
+	// sending it to decoding the response. It is zero for operations that
+	// were never sent, and reads merged into one request all report that
+	// request's duration.
+	Duration time.Duration
 }
 
 // ExecuteBatch executes multiple operations in sequence
 // This provides better performance than individual calls by reusing the connection
 func (c *Client) ExecuteBatch(operations []BatchOperation) []BatchResult {
+	return c.ExecuteBatchContext(context.Background(), operations)
+}
+
+// ExecuteBatchContext is ExecuteBatch with cancellation. The context is
+// checked before each operation; once it is done, that operation and all
+// remaining ones fail with the context's error without being sent.
+func (c *Client) ExecuteBatchContext(ctx context.Context, operations []BatchOperation) []BatchResult {
 	results := make([]BatchResult, len(operations))
 
-	for i, op := range operations {
-		result := BatchResult{Operation: op.Operation}
+	// The operations run one at a time on this connection, so they can all
+	// build requests and read responses in the same buffers
+	buffers := newBatchBuffers(operations)
+	for i := 0; i < len(operations); {
+		if err := ctx.Err(); err != nil {
+			results[i] = BatchResult{Operation: operations[i].Operation, Error: err}
+			i++
+			continue
+		}
 
-		switch op.Operation {
-		case "read_coils":
-			values, err := c.ReadCoils(op.SlaveID, op.Address, op.Quantity)
-			result.Values = values
-			result.Error = err
+		n := coalescedReads(operations, i)
+		if n == 1 {
+			results[i] = c.executeOperation(operations[i], buffers)
+		} else {
+			c.executeMergedRead(operations[i:i+n], results[i:i+n], buffers)
+		}
+		i += n
+	}
+
+	return results
+}
+
+// coalescedReads returns how many operations, starting at operations[start],
+// can be sent as a single read: the same read operation on the same slave,
+// each starting where the previous one ends, within the function's quantity
+// limit. It returns 1 when operations[start] cannot be merged.
+func coalescedReads(operations []BatchOperation, start int) int {
+	first := operations[start]
+	var limit int
+	switch first.Operation {
+	case "read_coils", "read_discrete":
+		limit = 2000
+	case "read_holding", "read_input":
+		limit = 125
+	default:
+		return 1
+	}
+
+	n, total := 1, int(first.Quantity)
+	if total == 0 || total > limit {
+		return 1
+	}
+	for _, op := range operations[start+1:] {
+		end := int(first.Address) + total
+		if op.Operation != first.Operation || op.SlaveID != first.SlaveID || int(op.Address) != end ||
+			op.Quantity == 0 || total+int(op.Quantity) > limit {
+			break
+		}
+		n++
+		total += int(op.Quantity)
+	}
+	return n
+}
+
+// executeMergedRead sends the reads in ops, as planned by coalescedReads, as
+// one request and splits its values among results. Each part is valid on its
+// own, so if the device rejects the combined range with an exception the
+// reads are retried one at a time to give each its own result.
+func (c *Client) executeMergedRead(ops []BatchOperation, results []BatchResult, buffers *batchBuffers) {
+	merged := ops[0]
+	last := ops[len(ops)-1]
+	merged.Quantity = last.Address + last.Quantity - merged.Address
+
+	result := c.executeOperation(merged, buffers)
+	var modbusErr *ModbusError
+	if errors.As(result.Error, &modbusErr) {
+		for i, op := range ops {
+			results[i] = c.executeOperation(op, buffers)
+		}
+		return
+	}
+
+	offset := 0
+	for i, op := range ops {
+		results[i] = BatchResult{Operation: op.Operation, Error: result.Error, Duration: result.Duration}
+		if result.Error != nil {
+			continue
+		}
+		end := offset + int(op.Quantity)
+		switch values := result.Values.(type) {
+		case []bool:
+			results[i].Values = values[offset:end:end]
+		case []uint16:
+			results[i].Values = values[offset:end:end]
+		}
+		offset = end
+	}
+}
 
-		case "read_holding":
-			values, err := c.ReadHoldingRegisters(op.SlaveID, op.Address, op.Quantity)
-			result.Values = values
-			result.Error = err
+// batchBuffers is the scratch space shared by the operations of a batch.
+// The request and response buffers are overwritten by every operation; the
+// result backings are handed out in pieces, one per successful read, so the
+// returned values never alias each other.
+type batchBuffers struct {
+	pdu       []byte   // Request PDU of the current operation
+	response  []byte   // Response frame of the current operation
+	registers []uint16 // Unused backing for register read results
+	coils     []bool   // Unused backing for coil and discrete input read results
+}
 
-		case "read_input":
-			values, err := c.ReadInputRegisters(op.SlaveID, op.Address, op.Quantity)
-			result.Values = values
-			result.Error = err
+// newBatchBuffers sizes the buffers for operations. The request and
+// response buffers fit the largest valid operation, and the result backings
+// cover every valid register and coil read, so a batch of reads decodes into
+// two allocations instead of one per operation.
+func newBatchBuffers(operations []BatchOperation) *batchBuffers {
+	var registers, coils, pduSize, responseSize int
+	fit := func(request, response int) {
+		if request > pduSize {
+			pduSize = request
+		}
+		if response > responseSize {
+			responseSize = response
+		}
+	}
 
+	for _, op := range operations {
+		switch op.Operation {
+		case "read_holding", "read_input":
+			if op.Quantity <= 125 {
+				registers += int(op.Quantity)
+				fit(5, 2+2*int(op.Quantity))
+			}
+		case "read_coils", "read_discrete":
+			if op.Quantity <= 2000 {
+				coils += int(op.Quantity)
+				fit(5, 2+(int(op.Quantity)+7)/8)
+			}
+		case "write_registers":
+			if values, ok := op.Values.([]uint16); ok && len(values) <= 123 {
+				fit(6+2*len(values), 5)
+			}
 		case "write_coils":
-			if coils, ok := op.Values.([]bool); ok {
-				result.Error = c.WriteMultipleCoils(op.SlaveID, op.Address, coils)
-			} else {
-				result.Error = fmt.Errorf("invalid values type for write_coils")
+			if values, ok := op.Values.([]bool); ok && len(values) <= 1968 {
+				fit(6+(len(values)+7)/8, 5)
 			}
+		}
+	}
 
-		case "write_registers":
-			if registers, ok := op.Values.([]uint16); ok {
-				result.Error = c.WriteMultipleRegisters(op.SlaveID, op.Address, registers)
-			} else {
-				result.Error = fmt.Errorf("invalid values type for write_registers")
+	return &batchBuffers{
+		pdu:       make([]byte, 0, pduSize),
+		response:  make([]byte, 0, 7+responseSize),
+		registers: make([]uint16, registers),
+		coils:     make([]bool, coils),
+	}
+}
+
+// takeRegisters returns n registers from the backing, allocating them if
+// it has run out or b is nil
+func (b *batchBuffers) takeRegisters(n int) []uint16 {
+	if b == nil || len(b.registers) < n {
+		return make([]uint16, n)
+	}
+	values := b.registers[:n:n]
+	b.registers = b.registers[n:]
+	return values
+}
+
+// takeCoils is takeRegisters for coil results
+func (b *batchBuffers) takeCoils(n int) []bool {
+	if b == nil || len(b.coils) < n {
+		return make([]bool, n)
+	}
+	values := b.coils[:n:n]
+	b.coils = b.coils[n:]
+	return values
+}
+
+// executeOperation runs a single batch operation, using buffers if it is
+// not nil
+func (c *Client) executeOperation(op BatchOperation, buffers *batchBuffers) BatchResult {
+	opts := requestOptions{buffers: buffers}
+	result := BatchResult{Operation: op.Operation}
+	timed := func(call func() error) {
+		start := time.Now()
+		result.Error = call()
+		result.Duration = time.Since(start)
+	}
+
+	switch op.Operation {
+	case "read_coils", "read_discrete":
+		funcCode := byte(FuncCodeReadCoils)
+		if op.Operation == "read_discrete" {
+			funcCode = FuncCodeReadDiscreteInputs
+		}
+		timed(func() error {
+			response, err := c.readBitsResponse(op.SlaveID, funcCode, op.Address, op.Quantity, opts)
+			if err != nil {
+				return err
 			}
+			coils := buffers.takeCoils(int(op.Quantity))
+			decodeCoils(response, coils)
+			result.Values = coils
+			return nil
+		})
 
-		default:
-			result.Error = fmt.Errorf("unknown operation: %s", op.Operation)
+	case "read_holding", "read_input":
+		funcCode := byte(FuncCodeReadHoldingRegisters)
+		if op.Operation == "read_input" {
+			funcCode = FuncCodeReadInputRegisters
+		}
+		timed(func() error {
+			response, err := c.readRegistersResponse(op.SlaveID, funcCode, op.Address, op.Quantity, opts)
+			if err != nil {
+				return err
+			}
+			registers := buffers.takeRegisters(int(op.Quantity))
+			decodeRegisters(response, registers)
+			result.Values = registers
+			return nil
+		})
+
+	case "write_coils":
+		if coils, ok := op.Values.([]bool); ok {
+			timed(func() error { return c.writeMultipleCoils(op.SlaveID, op.Address, coils, opts) })
+		} else {
+			result.Error = fmt.Errorf("invalid values type for write_coils")
+		}
+
+	case "write_registers":
+		if registers, ok := op.Values.([]uint16); ok {
+			timed(func() error { return c.writeMultipleRegisters(op.SlaveID, op.Address, registers, opts) })
+		} else {
+			result.Error = fmt.Errorf("invalid values type for write_registers")
+		}
+
+	case "read_float32":
+		timed(func() (err error) {
+			result.Values, err = c.ReadFloat32s(op.SlaveID, op.Address, int(op.Quantity), op.ByteOrder)
+			return err
+		})
+
+	case "write_float32":
+		if floats, ok := op.Values.([]float32); ok {
+			timed(func() error { return c.WriteFloat32s(op.SlaveID, op.Address, floats, op.ByteOrder) })
+		} else {
+			result.Error = fmt.Errorf("invalid values type for write_float32")
 		}
 
-		results[i] = result
+	default:
+		result.Error = fmt.Errorf("unknown operation: %s", op.Operation)
 	}
 
-	return results
+	return result
 }
 
 // ConnectionPool manages multiple Modbus connections for high-performance scenarios
@@ -438,53 +2336,219 @@ type ConnectionPool struct {
 	timeout time.Duration
 	pool    chan *Client
 	maxConn int
+	config  PoolConfig
+
+	mutex   sync.Mutex
+	clients []*Client // every connection owned by the pool, idle or checked out
+	closed  bool
+
+	stop          chan struct{} // closed by Close to stop background work
+	keepAliveDone chan struct{} // closed when the keep-alive loop exits
+}
+
+// PoolConfig holds configuration for a connection pool
+type PoolConfig struct {
+	Address        string        // TCP address (e.g., "192.168.1.100:502")
+	MaxConnections int           // Number of connections to keep (default 10)
+	Timeout        time.Duration // Operation and Get timeout (default 5s)
+
+	// MaxConsecutiveErrors, if positive, makes Put close and replace a
+	// connection whose last MaxConsecutiveErrors transactions all failed
+	// with transport errors. Modbus exceptions don't count as failures since
+	// the device still answered.
+	MaxConsecutiveErrors int
+
+	// KeepAliveInterval, if positive, pings every idle connection this often
+	// so firewalls and NAT don't drop it, replacing connections whose probe
+	// fails. Connections are taken out of the pool while probed, so Get never
+	// hands out one mid-probe.
+	KeepAliveInterval time.Duration
+
+	// KeepAliveSlaveID is the unit ID addressed by keep-alive, warm-up and
+	// GetHealthy probes (default 1)
+	KeepAliveSlaveID byte
+
+	// ProbeAddress is the holding register read by those probes (default 0);
+	// see ClientConfig.ProbeAddress
+	ProbeAddress uint16
+
+	// WarmUp makes NewConnectionPoolWithConfig probe every new connection
+	// before returning, so an address that accepts TCP connections but does
+	// not speak Modbus fails pool creation instead of the first request.
+	// Exception responses pass the probe, as the device did answer.
+	WarmUp bool
+
+	// ReconnectBackoff, if positive, makes replacing a failed connection
+	// lazy: the connection leaves the pool and is redialed in the background
+	// after this delay, doubling after each failed attempt up to 32 times the
+	// initial delay. Zero redials immediately and drops the connection if
+	// that fails.
+	ReconnectBackoff time.Duration
+
+	// ReconnectJitter adds a random delay of up to this much to every
+	// background redial so connections don't reconnect in lockstep
+	ReconnectJitter time.Duration
+
+	// SlaveTimeouts overrides Timeout for requests to the listed slave IDs on
+	// every pooled connection; see ClientConfig.SlaveTimeouts
+	SlaveTimeouts map[byte]time.Duration
+
+	// MinInterval is the minimum gap between requests on each pooled
+	// connection; see ClientConfig.MinInterval
+	MinInterval time.Duration
+}
+
+// ConnectionStats describes recent activity on a single pooled connection
+type ConnectionStats struct {
+	LastSlaveID    byte         // Slave ID of the most recent request
+	RecentSlaveIDs map[byte]int // Requests per slave ID over the recent window
+}
+
+// PoolStats holds diagnostic information about a connection pool
+type PoolStats struct {
+	Idle        int               // Connections currently waiting in the pool
+	Connections []ConnectionStats // Per-connection activity, in creation order
 }
 
 // NewConnectionPool creates a new connection pool
 func NewConnectionPool(address string, maxConnections int, timeout time.Duration) (*ConnectionPool, error) {
-	if maxConnections <= 0 {
-		maxConnections = 10
+	return NewConnectionPoolWithConfig(PoolConfig{
+		Address:        address,
+		MaxConnections: maxConnections,
+		Timeout:        timeout,
+	})
+}
+
+// NewConnectionPoolWithConfig creates a new connection pool from a full configuration
+func NewConnectionPoolWithConfig(config PoolConfig) (*ConnectionPool, error) {
+	if config.MaxConnections <= 0 {
+		config.MaxConnections = 10
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 5 * time.Second
 	}
-	if timeout == 0 {
-		timeout = 5 * time.Second
+	if config.KeepAliveSlaveID == 0 {
+		config.KeepAliveSlaveID = 1
 	}
 
 	pool := &ConnectionPool{
-		address: address,
-		timeout: timeout,
-		pool:    make(chan *Client, maxConnections),
-		maxConn: maxConnections,
+		address: config.Address,
+		timeout: config.Timeout,
+		pool:    make(chan *Client, config.MaxConnections),
+		maxConn: config.MaxConnections,
+		config:  config,
+		stop:    make(chan struct{}),
 	}
 
 	// Pre-create connections
-	for i := 0; i < maxConnections; i++ {
-		client, err := NewClient(ClientConfig{
-			Address: address,
-			Timeout: timeout,
-		})
+	for i := 0; i < config.MaxConnections; i++ {
+		client, err := pool.dial()
 		if err != nil {
 			// Close any existing connections
 			pool.Close()
 			return nil, fmt.Errorf("failed to create connection %d: %w", i, err)
 		}
+		pool.clients = append(pool.clients, client)
 		pool.pool <- client
+
+		if config.WarmUp {
+			if err := client.Ping(config.KeepAliveSlaveID); err != nil {
+				pool.Close()
+				return nil, fmt.Errorf("connection %d failed warm-up probe: %w", i, err)
+			}
+		}
+	}
+
+	if config.KeepAliveInterval > 0 {
+		pool.keepAliveDone = make(chan struct{})
+		go pool.keepAlive(config.KeepAliveInterval)
 	}
 
 	return pool, nil
 }
 
+// dial opens a new connection configured for the pool
+func (p *ConnectionPool) dial() (*Client, error) {
+	return NewClient(ClientConfig{
+		Address:       p.address,
+		Timeout:       p.timeout,
+		SlaveTimeouts: p.config.SlaveTimeouts,
+		MinInterval:   p.config.MinInterval,
+		ProbeAddress:  p.config.ProbeAddress,
+	})
+}
+
 // Get retrieves a connection from the pool
 func (p *ConnectionPool) Get() (*Client, error) {
+	return p.get(context.Background())
+}
+
+// get waits for an idle connection until the pool timeout or ctx ends
+func (p *ConnectionPool) get(ctx context.Context) (*Client, error) {
+	p.mutex.Lock()
+	closed := p.closed
+	p.mutex.Unlock()
+	if closed {
+		return nil, fmt.Errorf("connection pool is closed")
+	}
+
 	select {
 	case client := <-p.pool:
 		return client, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	case <-time.After(p.timeout):
 		return nil, fmt.Errorf("timeout waiting for connection")
 	}
 }
 
-// Put returns a connection to the pool
+// GetHealthy is Get that pings the connection before returning it. A
+// connection that fails the probe is replaced and the next one tried, up to
+// the pool size, so the caller receives a working connection or an error.
+// ctx bounds the wait for an idle connection.
+func (p *ConnectionPool) GetHealthy(ctx context.Context) (*Client, error) {
+	var candidate *Client
+	for attempt := 0; attempt < p.maxConn; attempt++ {
+		if candidate == nil {
+			var err error
+			if candidate, err = p.get(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := candidate.Ping(p.config.KeepAliveSlaveID); err == nil {
+			return candidate, nil
+		}
+		candidate = p.replace(candidate)
+	}
+
+	if candidate != nil {
+		p.Put(candidate)
+	}
+	return nil, fmt.Errorf("no healthy connection after %d attempts", p.maxConn)
+}
+
+// Put returns a connection to the pool. A connection that has exceeded
+// MaxConsecutiveErrors is closed and replaced with a fresh one instead, and
+// connections returned after Close are closed.
 func (p *ConnectionPool) Put(client *Client) {
+	if p.config.MaxConsecutiveErrors > 0 && client.ConsecutiveErrors() >= p.config.MaxConsecutiveErrors {
+		client = p.replace(client)
+		if client == nil {
+			return
+		}
+	}
+
+	// Hold the mutex while handing the connection back so Close cannot
+	// drain the pool between the closed check and the send
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		client.Close()
+		return
+	}
+
 	select {
 	case p.pool <- client:
 	default:
@@ -493,49 +2557,412 @@ func (p *ConnectionPool) Put(client *Client) {
 	}
 }
 
-// Close closes all connections in the pool
+// keepAlive probes idle connections every interval until the pool is closed
+func (p *ConnectionPool) keepAlive(interval time.Duration) {
+	defer close(p.keepAliveDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.probeIdle()
+		}
+	}
+}
+
+// probeIdle pings each connection idle at the time of the call, replacing
+// any whose probe fails with a transport error
+func (p *ConnectionPool) probeIdle() {
+	for n := len(p.pool); n > 0; n-- {
+		var client *Client
+		select {
+		case client = <-p.pool:
+		case <-p.stop:
+			return
+		default:
+			return // Everything else is checked out
+		}
+
+		if err := client.Ping(p.config.KeepAliveSlaveID); err != nil {
+			if client = p.replace(client); client == nil {
+				continue
+			}
+		}
+		p.Put(client)
+	}
+}
+
+// replace closes a failing connection and dials its replacement. It returns
+// nil, dropping the connection from the pool, if the new dial fails.
+func (p *ConnectionPool) replace(old *Client) *Client {
+	old.Close()
+	if p.config.ReconnectBackoff > 0 {
+		if p.remove(old) {
+			go p.redial()
+		}
+		return nil
+	}
+	replacement, err := p.dial()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, client := range p.clients {
+		if client != old {
+			continue
+		}
+		if err != nil {
+			p.clients = append(p.clients[:i], p.clients[i+1:]...)
+			return nil
+		}
+		p.clients[i] = replacement
+		return replacement
+	}
+
+	// Not one of ours; keep the replacement only if it was dialed
+	if err != nil {
+		return nil
+	}
+	p.clients = append(p.clients, replacement)
+	return replacement
+}
+
+// remove forgets a connection, reporting whether it belonged to the pool
+func (p *ConnectionPool) remove(old *Client) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, client := range p.clients {
+		if client == old {
+			p.clients = append(p.clients[:i], p.clients[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// redial reconnects a dropped connection in the background with exponential
+// backoff and jitter, adding it back to the pool once it is up
+func (p *ConnectionPool) redial() {
+	backoff := p.config.ReconnectBackoff
+	for {
+		delay := backoff
+		if p.config.ReconnectJitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(p.config.ReconnectJitter)))
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-time.After(delay):
+		}
+
+		client, err := p.dial()
+		if err == nil {
+			p.mutex.Lock()
+			p.clients = append(p.clients, client)
+			p.mutex.Unlock()
+			p.Put(client)
+			return
+		}
+
+		if backoff < 32*p.config.ReconnectBackoff {
+			backoff *= 2
+		}
+	}
+}
+
+// Stats returns a snapshot of the pool's connections and the slave IDs they
+// have recently served, useful for spotting uneven load across connections
+func (p *ConnectionPool) Stats() PoolStats {
+	p.mutex.Lock()
+	clients := append([]*Client(nil), p.clients...)
+	p.mutex.Unlock()
+
+	stats := PoolStats{
+		Idle:        len(p.pool),
+		Connections: make([]ConnectionStats, len(clients)),
+	}
+	for i, client := range clients {
+		stats.Connections[i] = ConnectionStats{
+			LastSlaveID:    client.LastSlaveID(),
+			RecentSlaveIDs: client.recentSlaveCounts(),
+		}
+	}
+
+	return stats
+}
+
+// Close closes all idle connections in the pool and stops keep-alive
+// probing. Connections still checked out are closed when they are Put back.
 func (p *ConnectionPool) Close() {
-	close(p.pool)
-	for client := range p.pool {
-		client.Close()
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return
+	}
+	p.closed = true
+	p.mutex.Unlock()
+
+	close(p.stop)
+	if p.keepAliveDone != nil {
+		<-p.keepAliveDone
+	}
+
+	for {
+		select {
+		case client := <-p.pool:
+			client.Close()
+		default:
+			return
+		}
 	}
 }
 
 // Example usage and helper functions
 
+// ByteOrder selects how a multi-register value is laid out across registers.
+// For 32-bit values the letters name the bytes of the value from most (A) to
+// least (D) significant in the order they appear on the wire, so 1.0
+// (0x3F800000) is sent as 3F 80 00 00 in ABCD and as 00 00 3F 80 in CDAB.
+// ByteOrderBig is ABCD and ByteOrderLittle is CDAB, the "word swap" order
+// used by Modicon PLCs.
+type ByteOrder string
+
+// Supported byte orders
+const (
+	ByteOrderBig    ByteOrder = "big"    // Most significant word first
+	ByteOrderLittle ByteOrder = "little" // Least significant word first
+
+	ByteOrderABCD ByteOrder = "ABCD" // Big-endian; same as ByteOrderBig
+	ByteOrderCDAB ByteOrder = "CDAB" // Word swap; same as ByteOrderLittle
+	ByteOrderBADC ByteOrder = "BADC" // Byte swap within each word
+	ByteOrderDCBA ByteOrder = "DCBA" // Little-endian; both swaps
+)
+
+var (
+	defaultByteOrderMutex sync.RWMutex
+	defaultByteOrder      = ByteOrderBig
+)
+
+// SetDefaultByteOrder sets the package-wide byte order used by helpers that
+// take no explicit order, and wherever an empty ByteOrder is passed
+func SetDefaultByteOrder(order ByteOrder) {
+	defaultByteOrderMutex.Lock()
+	defer defaultByteOrderMutex.Unlock()
+	defaultByteOrder = order
+}
+
+// DefaultByteOrder returns the package-wide default byte order
+func DefaultByteOrder() ByteOrder {
+	defaultByteOrderMutex.RLock()
+	defer defaultByteOrderMutex.RUnlock()
+	return defaultByteOrder
+}
+
+// resolve returns the order itself, or the package default when it is empty
+func (o ByteOrder) resolve() ByteOrder {
+	if o == "" {
+		return DefaultByteOrder()
+	}
+	return o
+}
+
+// wordLayout reports how a 32-bit byte order departs from ABCD: whether the
+// two registers are swapped, and whether the bytes within each are
+func wordLayout(byteOrder ByteOrder) (swapWords, swapBytes bool, err error) {
+	switch byteOrder.resolve() {
+	case ByteOrderBig, ByteOrderABCD:
+		return false, false, nil
+	case ByteOrderLittle, ByteOrderCDAB:
+		return true, false, nil
+	case ByteOrderBADC:
+		return false, true, nil
+	case ByteOrderDCBA:
+		return true, true, nil
+	default:
+		return false, false, fmt.Errorf("invalid byte order: %s", byteOrder)
+	}
+}
+
+// registersToUint32 combines two registers into a 32-bit value
+func registersToUint32(registers []uint16, byteOrder ByteOrder) (uint32, error) {
+	swapWords, swapBytes, err := wordLayout(byteOrder)
+	if err != nil {
+		return 0, err
+	}
+
+	high, low := registers[0], registers[1]
+	if swapWords {
+		high, low = low, high
+	}
+	if swapBytes {
+		high, low = bits.ReverseBytes16(high), bits.ReverseBytes16(low)
+	}
+	return uint32(high)<<16 | uint32(low), nil
+}
+
+// uint32ToRegisters splits a 32-bit value into two registers
+func uint32ToRegisters(value uint32, byteOrder ByteOrder) ([]uint16, error) {
+	swapWords, swapBytes, err := wordLayout(byteOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	high, low := uint16(value>>16), uint16(value)
+	if swapBytes {
+		high, low = bits.ReverseBytes16(high), bits.ReverseBytes16(low)
+	}
+	if swapWords {
+		high, low = low, high
+	}
+	return []uint16{high, low}, nil
+}
+
 // ReadFloat32 reads a 32-bit float from two consecutive registers
-func (c *Client) ReadFloat32(slaveID byte, address uint16, byteOrder string) (float32, error) {
-	registers, err := c.ReadHoldingRegisters(slaveID, address, 2)
+func (c *Client) ReadFloat32(slaveID byte, address uint16, byteOrder ByteOrder) (float32, error) {
+	return c.readFloat32(slaveID, FuncCodeReadHoldingRegisters, address, byteOrder)
+}
+
+// ReadInputFloat32 is ReadFloat32 for input registers (function code 0x04)
+func (c *Client) ReadInputFloat32(slaveID byte, address uint16, byteOrder ByteOrder) (float32, error) {
+	return c.readFloat32(slaveID, FuncCodeReadInputRegisters, address, byteOrder)
+}
+
+// readFloat32 implements the holding and input register float reads
+func (c *Client) readFloat32(slaveID, funcCode byte, address uint16, byteOrder ByteOrder) (float32, error) {
+	registers, err := c.readRegisters(slaveID, funcCode, address, 2, requestOptions{})
 	if err != nil {
 		return 0, err
 	}
 
-	var bits uint32
-	switch byteOrder {
-	case "big":
-		bits = uint32(registers[0])<<16 | uint32(registers[1])
-	case "little":
-		bits = uint32(registers[1])<<16 | uint32(registers[0])
-	default:
-		return 0, fmt.Errorf("invalid byte order: %s", byteOrder)
+	bits, err := registersToUint32(registers, byteOrder)
+	if err != nil {
+		return 0, err
 	}
 
 	return *(*float32)(unsafe.Pointer(&bits)), nil
 }
 
 // WriteFloat32 writes a 32-bit float to two consecutive registers
-func (c *Client) WriteFloat32(slaveID byte, address uint16, value float32, byteOrder string) error {
+func (c *Client) WriteFloat32(slaveID byte, address uint16, value float32, byteOrder ByteOrder) error {
 	bits := *(*uint32)(unsafe.Pointer(&value))
 
-	var registers []uint16
-	switch byteOrder {
-	case "big":
-		registers = []uint16{uint16(bits >> 16), uint16(bits & 0xFFFF)}
-	case "little":
-		registers = []uint16{uint16(bits & 0xFFFF), uint16(bits >> 16)}
-	default:
-		return fmt.Errorf("invalid byte order: %s", byteOrder)
+	registers, err := uint32ToRegisters(bits, byteOrder)
+	if err != nil {
+		return err
+	}
+
+	return c.WriteMultipleRegisters(slaveID, address, registers)
+}
+
+// ReadFloat32s reads count consecutive 32-bit floats (two registers each),
+// splitting the read into as many requests as the register limit requires.
+// Each request holds whole floats, so both words of a value are read
+// together.
+func (c *Client) ReadFloat32s(slaveID byte, address uint16, count int, byteOrder ByteOrder) ([]float32, error) {
+	return c.readFloat32s(slaveID, FuncCodeReadHoldingRegisters, address, count, byteOrder)
+}
+
+// ReadInputFloat32s is ReadFloat32s for input registers (function code 0x04)
+func (c *Client) ReadInputFloat32s(slaveID byte, address uint16, count int, byteOrder ByteOrder) ([]float32, error) {
+	return c.readFloat32s(slaveID, FuncCodeReadInputRegisters, address, count, byteOrder)
+}
+
+// readFloat32s implements the holding and input register bulk float reads
+func (c *Client) readFloat32s(slaveID, funcCode byte, address uint16, count int, byteOrder ByteOrder) ([]float32, error) {
+	if count <= 0 || count > 0xFFFF/2 {
+		return nil, fmt.Errorf("invalid float count: %d (must be 1-%d)", count, 0xFFFF/2)
+	}
+	if err := checkAddressRange(address, 2*count); err != nil {
+		return nil, err
+	}
+
+	registers, err := c.readRegistersChunked(slaveID, funcCode, address, uint16(2*count), 2)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]float32, count)
+	for i := range values {
+		bits, err := registersToUint32(registers[2*i:2*i+2], byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = math.Float32frombits(bits)
+	}
+
+	return values, nil
+}
+
+// WriteFloat32s writes consecutive 32-bit floats (two registers each),
+// splitting the write into as many requests as the register limit requires
+// without splitting a float. A failure part way through is reported as a
+// *PartialWriteError whose Written counts registers, always an even number:
+// Written/2 floats were stored.
+func (c *Client) WriteFloat32s(slaveID byte, address uint16, values []float32, byteOrder ByteOrder) error {
+	registers := make([]uint16, 0, 2*len(values))
+	for _, value := range values {
+		words, err := uint32ToRegisters(math.Float32bits(value), byteOrder)
+		if err != nil {
+			return err
+		}
+		registers = append(registers, words...)
+	}
+
+	return c.writeRegistersChunked(slaveID, address, registers, 2)
+}
+
+// ReadFloat32Default reads a 32-bit float using the package default byte order
+func (c *Client) ReadFloat32Default(slaveID byte, address uint16) (float32, error) {
+	return c.ReadFloat32(slaveID, address, DefaultByteOrder())
+}
+
+// WriteFloat32Default writes a 32-bit float using the package default byte order
+func (c *Client) WriteFloat32Default(slaveID byte, address uint16, value float32) error {
+	return c.WriteFloat32(slaveID, address, value, DefaultByteOrder())
+}
+
+// ReadInt16 reads holding registers and reinterprets each word as a signed
+// 16-bit value
+func (c *Client) ReadInt16(slaveID byte, address, quantity uint16) ([]int16, error) {
+	registers, err := c.ReadHoldingRegisters(slaveID, address, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]int16, len(registers))
+	for i, reg := range registers {
+		values[i] = int16(reg)
+	}
+
+	return values, nil
+}
+
+// ReadInt16Value reads a single signed 16-bit holding register
+func (c *Client) ReadInt16Value(slaveID byte, address uint16) (int16, error) {
+	values, err := c.ReadInt16(slaveID, address, 1)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+// WriteInt16 writes signed 16-bit values to consecutive holding registers
+func (c *Client) WriteInt16(slaveID byte, address uint16, values []int16) error {
+	registers := make([]uint16, len(values))
+	for i, value := range values {
+		registers[i] = uint16(value)
 	}
 
 	return c.WriteMultipleRegisters(slaveID, address, registers)
 }
+
+// WriteInt16Value writes a single signed 16-bit holding register
+func (c *Client) WriteInt16Value(slaveID byte, address uint16, value int16) error {
+	return c.WriteSingleRegister(slaveID, address, uint16(value))
+}
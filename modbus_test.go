@@ -1,6 +1,24 @@
 package modbus
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -16,6 +34,50 @@ func TestModbusError(t *testing.T) {
 	if err.Error() != expected {
 		t.Errorf("Expected error message '%s', got '%s'", expected, err.Error())
 	}
+
+	err.Details = []byte{0x1A, 0x2B}
+	expected = "Modbus exception: function=0x03, exception=0x02, details=1A 2B"
+	if err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%s'", expected, err.Error())
+	}
+}
+
+// TestExceptionDetails tests capturing vendor bytes after the exception code
+func TestExceptionDetails(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if unitID == 2 {
+			return []byte{pdu[0] | 0x80, ExceptionSlaveDeviceFailure, 0x42, 0x07}
+		}
+		return []byte{pdu[0] | 0x80, ExceptionSlaveDeviceFailure}
+	}
+	client := newTestClient(t, server)
+
+	tests := []struct {
+		slaveID byte
+		details []byte
+	}{
+		{1, nil},
+		{2, []byte{0x42, 0x07}},
+	}
+
+	for _, tt := range tests {
+		_, err := client.ReadHoldingRegisters(tt.slaveID, 0, 1)
+		var modbusErr *ModbusError
+		if !errors.As(err, &modbusErr) {
+			t.Fatalf("Slave %d: expected *ModbusError, got %v", tt.slaveID, err)
+		}
+		if modbusErr.ExceptionCode != ExceptionSlaveDeviceFailure || !bytes.Equal(modbusErr.Details, tt.details) {
+			t.Errorf("Slave %d: expected exception 0x04 with details %v, got 0x%02X with %v",
+				tt.slaveID, tt.details, modbusErr.ExceptionCode, modbusErr.Details)
+		}
+		if tt.details == nil && modbusErr.Details != nil {
+			t.Errorf("Slave %d: expected nil details, got %v", tt.slaveID, modbusErr.Details)
+		}
+		if !errors.Is(err, ErrSlaveDeviceFailure) {
+			t.Errorf("Slave %d: expected ErrSlaveDeviceFailure to match, got %v", tt.slaveID, err)
+		}
+	}
 }
 
 // TestClientConfig tests client configuration validation
@@ -53,6 +115,41 @@ func TestClientConfig(t *testing.T) {
 	}
 }
 
+// TestDialRetries tests connecting to a device that comes online late
+func TestDialRetries(t *testing.T) {
+	// Reserve a port, then free it until the device "boots"
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	if _, err := NewClient(ClientConfig{Address: address, Timeout: time.Second, DialRetries: 2, DialRetryDelay: time.Millisecond}); err == nil {
+		t.Fatal("Expected error with the device still down")
+	}
+
+	server := NewMockServer()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		relistener, err := net.Listen("tcp", address)
+		if err != nil {
+			t.Errorf("Failed to listen again: %v", err)
+			return
+		}
+		server.serve(t, relistener)
+	}()
+
+	client, err := NewClient(ClientConfig{Address: address, Timeout: time.Second, DialRetries: 10, DialRetryDelay: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected NewClient to connect once the device is up, got %v", err)
+	}
+	defer client.Close()
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Read failed: %v", err)
+	}
+}
+
 // TestValidateQuantity tests quantity validation for various operations
 func TestValidateQuantity(t *testing.T) {
 	// This would be used by a mock client for testing
@@ -98,6 +195,66 @@ func TestValidateQuantity(t *testing.T) {
 	}
 }
 
+// TestAddressRangeOverflow tests that ranges past the end of the address space are rejected
+func TestAddressRangeOverflow(t *testing.T) {
+	tests := []struct {
+		address  uint16
+		quantity int
+		wantErr  bool
+	}{
+		{0, 1, false},
+		{65535, 1, false},
+		{65534, 2, false},
+		{65535, 2, true},
+		{65530, 10, true},
+	}
+
+	for _, tt := range tests {
+		err := checkAddressRange(tt.address, tt.quantity)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkAddressRange(%d, %d) error = %v, wantErr %v",
+				tt.address, tt.quantity, err, tt.wantErr)
+		}
+	}
+
+	// The check happens before anything is sent, so no connection is needed
+	client := &Client{}
+	if _, err := client.ReadHoldingRegisters(1, 65530, 10); err == nil {
+		t.Error("Expected ReadHoldingRegisters to reject an overflowing range")
+	}
+	if err := client.WriteMultipleCoils(1, 65535, []bool{true, true}); err == nil {
+		t.Error("Expected WriteMultipleCoils to reject an overflowing range")
+	}
+
+	// Every read and write reports the offending range
+	calls := []struct {
+		name     string
+		call     func() error
+		address  uint16
+		quantity int
+	}{
+		{"ReadCoils", func() error { _, err := client.ReadCoils(1, 65530, 10); return err }, 65530, 10},
+		{"ReadDiscreteInputs", func() error { _, err := client.ReadDiscreteInputs(1, 65535, 2); return err }, 65535, 2},
+		{"ReadInputRegisters", func() error { _, err := client.ReadInputRegisters(1, 65500, 100); return err }, 65500, 100},
+		{"ReadFloat32", func() error { _, err := client.ReadFloat32(1, 65535, ByteOrderBig); return err }, 65535, 2},
+		{"ReadFloat32s", func() error { _, err := client.ReadFloat32s(1, 65534, 2, ByteOrderBig); return err }, 65534, 4},
+		{"WriteMultipleRegisters", func() error { return client.WriteMultipleRegisters(1, 65535, []uint16{1, 2}) }, 65535, 2},
+		{"WriteRegistersChunked", func() error { return client.WriteRegistersChunked(1, 65000, make([]uint16, 1000)) }, 65000, 1000},
+		{"ReadCoilsChunked", func() error { _, err := client.ReadCoilsChunked(1, 65000, 3000); return err }, 65000, 3000},
+	}
+	for _, tt := range calls {
+		err := tt.call()
+		var rangeErr *AddressRangeError
+		if !errors.As(err, &rangeErr) || !errors.Is(err, ErrAddressRange) {
+			t.Errorf("%s: expected *AddressRangeError, got %v", tt.name, err)
+			continue
+		}
+		if rangeErr.Address != tt.address || rangeErr.Quantity != tt.quantity {
+			t.Errorf("%s: expected range %d+%d, got %d+%d", tt.name, tt.address, tt.quantity, rangeErr.Address, rangeErr.Quantity)
+		}
+	}
+}
+
 // TestBatchOperation tests batch operation structure
 func TestBatchOperation(t *testing.T) {
 	operations := []BatchOperation{
@@ -273,6 +430,13 @@ func BenchmarkRegisterConversion(b *testing.B) {
 type MockServer struct {
 	coils     map[uint16]bool
 	registers map[uint16]uint16
+
+	mutex sync.Mutex
+	conns []net.Conn // accepted connections, for simulating drops
+	// handler, when set, is consulted before the built-in behavior; returning
+	// nil falls through to the default handling of the request, and an empty
+	// non-nil slice suppresses the response entirely
+	handler func(unitID byte, pdu []byte) []byte
 }
 
 // NewMockServer creates a new mock server
@@ -283,6 +447,115 @@ func NewMockServer() *MockServer {
 	}
 }
 
+// start serves the mock over TCP on a loopback port and returns its address.
+// The listener is closed when the test finishes.
+func (s *MockServer) start(t testing.TB) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	return s.serve(t, listener)
+}
+
+// serve accepts connections on listener until the test finishes and returns
+// the listener's address
+func (s *MockServer) serve(t testing.TB, listener net.Listener) string {
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mutex.Lock()
+			s.conns = append(s.conns, conn)
+			s.mutex.Unlock()
+			go s.serveConn(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// dropConnections closes every connection accepted so far, as a device
+// reboot would
+func (s *MockServer) dropConnections() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// serveConn answers MBAP framed requests on conn until it is closed
+func (s *MockServer) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	header := make([]byte, 7)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		pdu := make([]byte, binary.BigEndian.Uint16(header[4:6])-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+
+		response := s.handle(header[6], pdu)
+		if len(response) == 0 || header[6] == BroadcastSlaveID {
+			continue // Broadcasts and silent devices get no answer
+		}
+
+		frame := make([]byte, 7, 7+len(response))
+		copy(frame, header[0:4])
+		binary.BigEndian.PutUint16(frame[4:6], uint16(len(response)+1))
+		frame[6] = header[6]
+		if _, err := conn.Write(append(frame, response...)); err != nil {
+			return
+		}
+	}
+}
+
+// handle builds the response PDU for a request addressed to unitID
+func (s *MockServer) handle(unitID byte, pdu []byte) []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.handler != nil {
+		if response := s.handler(unitID, pdu); response != nil {
+			return response
+		}
+	}
+
+	return handleMemoryDevice(s.coils, s.registers, pdu)
+}
+
+// newTestClient starts server and returns a client connected to it
+func newTestClient(t *testing.T, server *MockServer) *Client {
+	t.Helper()
+	return newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second})
+}
+
+// newTestClientWithConfig is like newTestClient but lets the caller supply the
+// client configuration; the address is filled in from the started server
+func newTestClientWithConfig(t *testing.T, server *MockServer, config ClientConfig) *Client {
+	t.Helper()
+
+	config.Address = server.start(t)
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to connect to mock server: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
 // TestMockServer tests the mock server functionality
 func TestMockServer(t *testing.T) {
 	server := NewMockServer()
@@ -330,6 +603,2780 @@ func TestConnectionPoolBasics(t *testing.T) {
 	}
 }
 
+// TestLastSlaveID tests that the client remembers the most recently targeted slave
+func TestLastSlaveID(t *testing.T) {
+	client := newTestClient(t, NewMockServer())
+
+	for _, slaveID := range []byte{1, 7, 3} {
+		if _, err := client.ReadHoldingRegisters(slaveID, 0, 1); err != nil {
+			t.Fatalf("ReadHoldingRegisters(%d) failed: %v", slaveID, err)
+		}
+		if got := client.LastSlaveID(); got != slaveID {
+			t.Errorf("Expected last slave ID %d, got %d", slaveID, got)
+		}
+	}
+}
+
+// TestConnectionPoolStats tests the per-connection slave breakdown in pool stats
+func TestConnectionPoolStats(t *testing.T) {
+	address := NewMockServer().start(t)
+
+	pool, err := NewConnectionPool(address, 2, time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	client, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	for _, slaveID := range []byte{1, 2, 2} {
+		if _, err := client.ReadCoils(slaveID, 0, 8); err != nil {
+			t.Fatalf("ReadCoils(%d) failed: %v", slaveID, err)
+		}
+	}
+
+	stats := pool.Stats()
+	if stats.Idle != 1 {
+		t.Errorf("Expected 1 idle connection, got %d", stats.Idle)
+	}
+	if len(stats.Connections) != 2 {
+		t.Fatalf("Expected 2 connections, got %d", len(stats.Connections))
+	}
+
+	var used *ConnectionStats
+	for i := range stats.Connections {
+		if len(stats.Connections[i].RecentSlaveIDs) > 0 {
+			used = &stats.Connections[i]
+		}
+	}
+	if used == nil {
+		t.Fatal("Expected one connection to report recent slaves")
+	}
+	if used.LastSlaveID != 2 {
+		t.Errorf("Expected last slave ID 2, got %d", used.LastSlaveID)
+	}
+	if used.RecentSlaveIDs[1] != 1 || used.RecentSlaveIDs[2] != 2 {
+		t.Errorf("Unexpected recent slave breakdown: %v", used.RecentSlaveIDs)
+	}
+
+	pool.Put(client)
+}
+
+// TestOnTransaction tests that the transaction hook sees the raw frames
+func TestOnTransaction(t *testing.T) {
+	server := NewMockServer()
+	server.registers[4] = 0x1234
+
+	var requests, responses [][]byte
+	var errs []error
+	client := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout: time.Second,
+		OnTransaction: func(request, response []byte, err error) {
+			requests = append(requests, request)
+			responses = append(responses, response)
+			errs = append(errs, err)
+		},
+	})
+
+	if _, err := client.ReadHoldingRegisters(1, 4, 1); err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if _, err := client.sendRequest(1, []byte{0x41, 0x00, 0x00, 0x00, 0x00}); err == nil {
+		t.Fatal("Expected exception for unsupported function")
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 hook calls, got %d", len(requests))
+	}
+
+	// The transaction ID starts at a random value, so frames are compared
+	// after it and the response must echo the request's ID
+	expectedRequest := []byte{0x00, 0x00, 0x00, 0x06, 0x01, 0x03, 0x00, 0x04, 0x00, 0x01}
+	if string(requests[0][2:]) != string(expectedRequest) {
+		t.Errorf("Expected request frame ?? ?? % X, got % X", expectedRequest, requests[0])
+	}
+	expectedResponse := []byte{0x00, 0x00, 0x00, 0x05, 0x01, 0x03, 0x02, 0x12, 0x34}
+	if string(responses[0][2:]) != string(expectedResponse) {
+		t.Errorf("Expected response frame ?? ?? % X, got % X", expectedResponse, responses[0])
+	}
+	if string(responses[0][0:2]) != string(requests[0][0:2]) {
+		t.Errorf("Expected response to echo transaction ID % X, got % X", requests[0][0:2], responses[0][0:2])
+	}
+	if errs[0] != nil {
+		t.Errorf("Expected nil error for first transaction, got %v", errs[0])
+	}
+
+	if _, ok := errs[1].(*ModbusError); !ok {
+		t.Errorf("Expected *ModbusError for second transaction, got %v", errs[1])
+	}
+	if len(responses[1]) != 9 || responses[1][7] != 0xC1 {
+		t.Errorf("Expected exception response frame, got % X", responses[1])
+	}
+}
+
+// TestWriteTransactionRollback tests that earlier writes are undone when a later one fails
+func TestWriteTransactionRollback(t *testing.T) {
+	server := NewMockServer()
+	server.registers[10] = 1
+	server.registers[20] = 2
+	server.registers[30] = 3
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] == FuncCodeWriteMultipleRegisters && binary.BigEndian.Uint16(pdu[1:3]) == 30 {
+			return []byte{pdu[0] | 0x80, ExceptionSlaveDeviceFailure}
+		}
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	err := client.WriteTransaction(1, []WriteStep{
+		{Address: 10, Values: []uint16{100}},
+		{Address: 20, Values: []uint16{200}},
+		{Address: 30, Values: []uint16{300}},
+	})
+
+	txErr, ok := err.(*TransactionError)
+	if !ok {
+		t.Fatalf("Expected *TransactionError, got %v", err)
+	}
+	if txErr.Step != 2 {
+		t.Errorf("Expected failure at step 2, got %d", txErr.Step)
+	}
+	if txErr.RollbackErr != nil {
+		t.Errorf("Expected clean rollback, got %v", txErr.RollbackErr)
+	}
+	if _, ok := txErr.Err.(*ModbusError); !ok {
+		t.Errorf("Expected underlying *ModbusError, got %v", txErr.Err)
+	}
+
+	for address, expected := range map[uint16]uint16{10: 1, 20: 2, 30: 3} {
+		if got := server.registers[address]; got != expected {
+			t.Errorf("Register %d: expected %d after rollback, got %d", address, expected, got)
+		}
+	}
+}
+
+// TestSendRawPDU tests sending a vendor-specific function code
+func TestSendRawPDU(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] == 0x65 {
+			return []byte{0x65, 0xAB, pdu[1]}
+		}
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	response, err := client.SendRawPDU(1, []byte{0x65, 0x07})
+	if err != nil {
+		t.Fatalf("SendRawPDU failed: %v", err)
+	}
+	if string(response) != string([]byte{0x65, 0xAB, 0x07}) {
+		t.Errorf("Unexpected response PDU % X", response)
+	}
+
+	_, err = client.SendRawPDU(1, []byte{0x66, 0x00, 0x00, 0x00, 0x00})
+	if merr, ok := err.(*ModbusError); !ok || merr.FunctionCode != 0x66 {
+		t.Errorf("Expected exception for function 0x66, got %v", err)
+	}
+
+	if _, err := client.SendRawPDU(1, nil); err == nil {
+		t.Error("Expected error for empty PDU")
+	}
+}
+
+// TestWriteSingleCoilEcho tests validation of the WriteSingleCoil echo
+func TestWriteSingleCoilEcho(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   bool
+		echo    uint16
+		wantErr bool
+	}{
+		{"standard on", true, 0xFF00, false},
+		{"nonstandard on", true, 0x0001, false},
+		{"off", false, 0x0000, false},
+		{"on echoed as off", true, 0x0000, true},
+		{"off echoed as on", false, 0x0001, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewMockServer()
+			server.handler = func(unitID byte, pdu []byte) []byte {
+				response := append([]byte(nil), pdu...)
+				binary.BigEndian.PutUint16(response[3:5], tt.echo)
+				return response
+			}
+			client := newTestClient(t, server)
+
+			err := client.WriteSingleCoil(1, 3, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WriteSingleCoil() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestErrorRates tests per-function-code error rates over the rolling window
+func TestErrorRates(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if binary.BigEndian.Uint16(pdu[1:3]) == 999 {
+			return []byte{pdu[0] | 0x80, ExceptionIllegalDataAddress}
+		}
+		return nil
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout:         time.Second,
+		ErrorRateWindow: 4,
+	})
+
+	// Holding registers: 3 successes, 1 failure
+	for _, address := range []uint16{0, 999, 1, 2} {
+		client.ReadHoldingRegisters(1, address, 1)
+	}
+	// Coils: 1 success, 1 failure
+	for _, address := range []uint16{0, 999} {
+		client.ReadCoils(1, address, 1)
+	}
+
+	rates := client.ErrorRates()
+	if rates[FuncCodeReadHoldingRegisters] != 0.25 {
+		t.Errorf("Expected holding register error rate 0.25, got %v", rates[FuncCodeReadHoldingRegisters])
+	}
+	if rates[FuncCodeReadCoils] != 0.5 {
+		t.Errorf("Expected coil error rate 0.5, got %v", rates[FuncCodeReadCoils])
+	}
+	if _, ok := rates[FuncCodeReadInputRegisters]; ok {
+		t.Error("Expected no rate for an unused function code")
+	}
+
+	// The failure rolls out of the window after four more successes
+	for i := 0; i < 4; i++ {
+		client.ReadHoldingRegisters(1, 0, 1)
+	}
+	if rate := client.ErrorRates()[FuncCodeReadHoldingRegisters]; rate != 0 {
+		t.Errorf("Expected holding register error rate 0 after window rolled, got %v", rate)
+	}
+}
+
+// TestTransportErrorCauses tests telling a closed connection from a timeout
+func TestTransportErrorCauses(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if unitID == 2 {
+			return []byte{} // Never answer
+		}
+		return nil
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: 50 * time.Millisecond})
+
+	_, err := client.ReadHoldingRegisters(2, 0, 1)
+	if !errors.Is(err, ErrTimeout) || errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("Expected ErrTimeout only, got %v", err)
+	}
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Expected the underlying deadline error to be kept, got %v", err)
+	}
+
+	server.dropConnections()
+	_, err = client.ReadHoldingRegisters(1, 0, 1)
+	if !errors.Is(err, ErrConnectionClosed) || errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrConnectionClosed only, got %v", err)
+	}
+
+	client.Close()
+	_, err = client.ReadHoldingRegisters(1, 0, 1)
+	if !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("Expected ErrConnectionClosed after Close, got %v", err)
+	}
+
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); errors.Is(err, ErrIllegalFunction) {
+		t.Errorf("Expected transport error not to match exception sentinels, got %v", err)
+	}
+}
+
+// TestTransportErrorClassification tests which failures make the client
+// reconnect
+func TestTransportErrorClassification(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transport bool
+	}{
+		{"nil", nil, false},
+		{"exception", &ModbusError{FunctionCode: 3, ExceptionCode: 2}, false},
+		{"send failure", &connError{"failed to send request", io.EOF}, true},
+		{"wrapped read failure", fmt.Errorf("gave up: %w", &connError{"failed to read response header", io.EOF}), true},
+		{"idle closed", ErrIdleClosed, true},
+		{"cancelled", context.Canceled, false},
+		{"context deadline", fmt.Errorf("waiting: %w", context.DeadlineExceeded), false},
+		{"interval deadline", fmt.Errorf("%w: minimum request interval would pass the deadline", ErrTimeout), false},
+		{"mismatch", fmt.Errorf("transaction ID mismatch: expected 1, got 2"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransportError(tt.err); got != tt.transport {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.transport, got)
+		}
+	}
+
+	// A mismatched response on a working link keeps the connection
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if unitID == 2 {
+			return []byte{FuncCodeReadInputRegisters, 2, 0, 0}
+		}
+		return nil
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second, AutoReconnect: true, MinInterval: time.Second})
+	if _, err := client.ReadHoldingRegisters(2, 0, 1); err == nil || !strings.Contains(err.Error(), "function code mismatch") {
+		t.Errorf("Expected function code mismatch, got %v", err)
+	}
+
+	// Neither is running out of time waiting for MinInterval
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client.retryPolicy = &RetryPolicy{MaxAttempts: 5, Backoff: time.Second, MaxBackoff: time.Second}
+	start := time.Now()
+	if _, err := client.WaitForRegister(ctx, 1, 0, func(uint16) bool { return true }, 0); err == nil {
+		t.Error("Expected the wait for MinInterval to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected cancellation to end the wait at once, took %v", elapsed)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if len(server.conns) != 1 {
+		t.Errorf("Expected 1 connection, got %d", len(server.conns))
+	}
+}
+
+// TestClientStats tests the client's running totals
+func TestClientStats(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		switch binary.BigEndian.Uint16(pdu[1:3]) {
+		case 999:
+			return []byte{pdu[0] | 0x80, ExceptionIllegalDataAddress}
+		case 500:
+			time.Sleep(200 * time.Millisecond)
+		}
+		return nil
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: 100 * time.Millisecond})
+
+	client.ReadHoldingRegisters(1, 0, 1)   // 12 bytes out, 11 back
+	client.ReadHoldingRegisters(1, 999, 1) // 12 bytes out, 9 back
+	client.ReadCoils(1, 999, 1)            // 12 bytes out, 9 back
+	client.ReadHoldingRegisters(1, 500, 1) // 12 bytes out, times out
+
+	stats := client.Stats()
+	if stats.Requests != 4 {
+		t.Errorf("Expected 4 requests, got %d", stats.Requests)
+	}
+	if stats.Timeouts != 1 {
+		t.Errorf("Expected 1 timeout, got %d", stats.Timeouts)
+	}
+	if stats.Exceptions[ExceptionIllegalDataAddress] != 2 || len(stats.Exceptions) != 1 {
+		t.Errorf("Expected 2 illegal data address exceptions, got %v", stats.Exceptions)
+	}
+	if stats.BytesSent != 48 || stats.BytesReceived != 29 {
+		t.Errorf("Expected 48 bytes sent and 29 received, got %d and %d", stats.BytesSent, stats.BytesReceived)
+	}
+
+	// The snapshot is a copy
+	stats.Exceptions[ExceptionIllegalFunction] = 1
+	if _, ok := client.Stats().Exceptions[ExceptionIllegalFunction]; ok {
+		t.Error("Expected Stats to return a copy of the exception counts")
+	}
+}
+
+// TestExceptionRetries tests re-issuing requests on retryable exceptions
+func TestExceptionRetries(t *testing.T) {
+	server := NewMockServer()
+	var requests int
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		requests++
+		switch binary.BigEndian.Uint16(pdu[1:3]) {
+		case 0: // Busy twice, then answers
+			if requests <= 2 {
+				return []byte{pdu[0] | 0x80, ExceptionSlaveDeviceBusy}
+			}
+		case 1:
+			return []byte{pdu[0] | 0x80, ExceptionIllegalDataAddress}
+		case 2:
+			return []byte{pdu[0] | 0x80, ExceptionAcknowledge}
+		}
+		return nil
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout:             time.Second,
+		ExceptionRetries:    3,
+		ExceptionRetryDelay: time.Millisecond,
+	})
+	count := func() int {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		n := requests
+		requests = 0
+		return n
+	}
+
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Expected busy device to be retried until it answers, got %v", err)
+	}
+	if n := count(); n != 3 {
+		t.Errorf("Expected 3 requests, got %d", n)
+	}
+
+	if _, err := client.ReadHoldingRegisters(1, 1, 1); !errors.Is(err, ErrIllegalDataAddress) {
+		t.Errorf("Expected illegal data address, got %v", err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("Expected a permanent exception not to be retried, got %d requests", n)
+	}
+
+	if _, err := client.ReadHoldingRegisters(1, 2, 1); !errors.Is(err, ErrAcknowledge) {
+		t.Errorf("Expected acknowledge once retries run out, got %v", err)
+	}
+	if n := count(); n != 4 {
+		t.Errorf("Expected 1 request and 3 retries, got %d requests", n)
+	}
+}
+
+// TestExceptionRetryCancel tests that cancelling the context ends the wait
+// between exception retries
+func TestExceptionRetryCancel(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		return []byte{pdu[0] | 0x80, ExceptionSlaveDeviceBusy}
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout:             time.Second,
+		ExceptionRetries:    3,
+		ExceptionRetryDelay: time.Second,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.WaitForRegister(ctx, 1, 0, func(uint16) bool { return true }, time.Millisecond)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected cancel to cut the retry delay short, took %v", elapsed)
+	}
+}
+
+// TestRegisterBit tests reading and toggling single register bits
+func TestRegisterBit(t *testing.T) {
+	for _, maskWrite := range []bool{true, false} {
+		name := "mask write"
+		if !maskWrite {
+			name = "read-modify-write fallback"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			server := NewMockServer()
+			server.registers[7] = 0x8001
+			var maskWrites, singleWrites int
+			server.handler = func(unitID byte, pdu []byte) []byte {
+				switch pdu[0] {
+				case FuncCodeMaskWriteRegister:
+					maskWrites++
+					if !maskWrite {
+						return []byte{pdu[0] | 0x80, ExceptionIllegalFunction}
+					}
+				case FuncCodeWriteSingleRegister:
+					singleWrites++
+				}
+				return nil
+			}
+			client := newTestClient(t, server)
+
+			for bit, expected := range map[int]bool{0: true, 1: false, 15: true} {
+				value, err := client.ReadRegisterBit(1, 7, bit)
+				if err != nil {
+					t.Fatalf("ReadRegisterBit(%d) failed: %v", bit, err)
+				}
+				if value != expected {
+					t.Errorf("Bit %d: expected %v, got %v", bit, expected, value)
+				}
+			}
+
+			if err := client.WriteRegisterBit(1, 7, 3, true); err != nil {
+				t.Fatalf("WriteRegisterBit set failed: %v", err)
+			}
+			if err := client.WriteRegisterBit(1, 7, 15, false); err != nil {
+				t.Fatalf("WriteRegisterBit clear failed: %v", err)
+			}
+			if got := server.registers[7]; got != 0x0009 {
+				t.Errorf("Expected register 0x0009, got 0x%04X", got)
+			}
+
+			if maskWrite && (maskWrites != 2 || singleWrites != 0) {
+				t.Errorf("Expected 2 mask writes, got %d mask and %d single writes", maskWrites, singleWrites)
+			}
+			if !maskWrite && (maskWrites != 1 || singleWrites != 2) {
+				t.Errorf("Expected 1 mask write probe and 2 single writes, got %d and %d", maskWrites, singleWrites)
+			}
+		})
+	}
+
+	// On a gateway, one slave without mask writes doesn't affect the others
+	server := NewMockServer()
+	maskWrites := make(map[byte]int)
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] == FuncCodeMaskWriteRegister {
+			maskWrites[unitID]++
+			if unitID == 1 {
+				return []byte{pdu[0] | 0x80, ExceptionIllegalFunction}
+			}
+		}
+		return nil
+	}
+	gateway := newTestClient(t, server)
+	for _, slaveID := range []byte{1, 2, 1, 2} {
+		if err := gateway.WriteRegisterBit(slaveID, 7, 3, true); err != nil {
+			t.Fatalf("WriteRegisterBit(slave %d) failed: %v", slaveID, err)
+		}
+	}
+	if maskWrites[1] != 1 || maskWrites[2] != 2 {
+		t.Errorf("Expected 1 mask write probe to slave 1 and 2 mask writes to slave 2, got %v", maskWrites)
+	}
+
+	client := &Client{}
+	if _, err := client.ReadRegisterBit(1, 0, 16); err == nil {
+		t.Error("Expected error for bit 16")
+	}
+	if err := client.WriteRegisterBit(1, 0, -1, true); err == nil {
+		t.Error("Expected error for bit -1")
+	}
+}
+
+// TestDefaultByteOrder tests the package-wide byte order and per-call overrides
+func TestDefaultByteOrder(t *testing.T) {
+	defer SetDefaultByteOrder(DefaultByteOrder())
+
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	// 1.0 is 0x3F800000; store it low word first
+	server.registers[0] = 0x0000
+	server.registers[1] = 0x3F80
+
+	SetDefaultByteOrder(ByteOrderLittle)
+	if DefaultByteOrder() != ByteOrderLittle {
+		t.Fatalf("Expected default byte order little, got %s", DefaultByteOrder())
+	}
+
+	value, err := client.ReadFloat32Default(1, 0)
+	if err != nil {
+		t.Fatalf("ReadFloat32Default failed: %v", err)
+	}
+	if value != 1.0 {
+		t.Errorf("Expected 1.0 with default order, got %v", value)
+	}
+
+	value, err = client.ReadFloat32(1, 0, ByteOrderBig)
+	if err != nil {
+		t.Fatalf("ReadFloat32 failed: %v", err)
+	}
+	if value == 1.0 {
+		t.Error("Expected explicit big order to override the default")
+	}
+
+	if err := client.WriteFloat32Default(1, 10, 1.0); err != nil {
+		t.Fatalf("WriteFloat32Default failed: %v", err)
+	}
+	if server.registers[10] != 0x0000 || server.registers[11] != 0x3F80 {
+		t.Errorf("Expected low word first, got 0x%04X 0x%04X", server.registers[10], server.registers[11])
+	}
+
+	if _, err := client.ReadFloat32(1, 0, "middle"); err == nil {
+		t.Error("Expected error for invalid byte order")
+	}
+}
+
+// TestWordOrders tests 1.0 (0x3F800000) in each 32-bit byte order
+func TestWordOrders(t *testing.T) {
+	tests := []struct {
+		order     ByteOrder
+		registers []uint16
+	}{
+		{ByteOrderABCD, []uint16{0x3F80, 0x0000}},
+		{ByteOrderBig, []uint16{0x3F80, 0x0000}},
+		{ByteOrderCDAB, []uint16{0x0000, 0x3F80}},
+		{ByteOrderLittle, []uint16{0x0000, 0x3F80}},
+		{ByteOrderBADC, []uint16{0x803F, 0x0000}},
+		{ByteOrderDCBA, []uint16{0x0000, 0x803F}},
+	}
+
+	device := NewMemoryTransport()
+	client := NewClientWithTransport(device, ClientConfig{})
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			device.SetRegister(0, tt.registers[0])
+			device.SetRegister(1, tt.registers[1])
+			value, err := client.ReadFloat32(1, 0, tt.order)
+			if err != nil {
+				t.Fatalf("ReadFloat32 failed: %v", err)
+			}
+			if value != 1.0 {
+				t.Errorf("Expected 1.0, got %v", value)
+			}
+
+			if err := client.WriteFloat32(1, 10, 1.0, tt.order); err != nil {
+				t.Fatalf("WriteFloat32 failed: %v", err)
+			}
+			if device.Register(10) != tt.registers[0] || device.Register(11) != tt.registers[1] {
+				t.Errorf("Expected %04X, got [%04X %04X]", tt.registers, device.Register(10), device.Register(11))
+			}
+		})
+	}
+}
+
+// TestBroadcastWrites tests that writes to unit ID 0 don't wait for a response
+func TestBroadcastWrites(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: 200 * time.Millisecond})
+
+	start := time.Now()
+	if err := client.WriteSingleRegister(BroadcastSlaveID, 5, 42); err != nil {
+		t.Fatalf("Broadcast WriteSingleRegister failed: %v", err)
+	}
+	if err := client.WriteMultipleCoils(BroadcastSlaveID, 0, []bool{true, true}); err != nil {
+		t.Fatalf("Broadcast WriteMultipleCoils failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("Broadcast writes waited for a response (%v)", elapsed)
+	}
+
+	// A normal request afterwards must not be confused by the broadcasts
+	registers, err := client.ReadHoldingRegisters(1, 5, 1)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters after broadcast failed: %v", err)
+	}
+	if registers[0] != 42 {
+		t.Errorf("Expected broadcast value 42, got %d", registers[0])
+	}
+
+	if _, err := client.ReadHoldingRegisters(BroadcastSlaveID, 0, 1); err == nil {
+		t.Error("Expected broadcast read to be rejected")
+	}
+}
+
+// TestSetTimeout tests changing the timeout of a live client
+func TestSetTimeout(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		return []byte{} // Never answer
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: 10 * time.Second})
+
+	client.SetTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err == nil {
+		t.Fatal("Expected timeout error from silent device")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the shortened timeout to apply, request took %v", elapsed)
+	}
+}
+
+// TestReadEventQueue tests chronological ordering of a wrapped event queue
+func TestReadEventQueue(t *testing.T) {
+	server := NewMockServer()
+	server.registers[100] = 2 // Next write goes to slot 2, so slot 2 is the oldest
+	codes := []uint16{4, 5, 1, 2, 3}
+	for i, code := range codes {
+		server.registers[200+uint16(i)] = code
+	}
+	client := newTestClient(t, server)
+
+	labels := map[uint16]string{1: "idle", 2: "starting", 3: "running", 4: "stopping", 5: "fault"}
+	events, err := client.ReadEventQueue(1, 100, 200, len(codes), func(code uint16) string {
+		return labels[code]
+	})
+	if err != nil {
+		t.Fatalf("ReadEventQueue failed: %v", err)
+	}
+
+	expected := []string{"idle", "starting", "running", "stopping", "fault"}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("Event %d: expected %q, got %q", i, expected[i], events[i])
+		}
+	}
+
+	server.registers[100] = 5
+	if _, err := client.ReadEventQueue(1, 100, 200, len(codes), nil); err == nil {
+		t.Error("Expected error for head outside the buffer")
+	}
+}
+
+// TestPerCallTimeout tests that a per-call timeout overrides the client timeout
+func TestPerCallTimeout(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if binary.BigEndian.Uint16(pdu[1:3]) == 1 {
+			time.Sleep(150 * time.Millisecond) // Slow operation
+		}
+		return nil
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: 50 * time.Millisecond})
+
+	if _, err := client.ReadHoldingRegistersTimeout(1, 1, 1, time.Second); err != nil {
+		t.Fatalf("Expected slow read to succeed with a longer timeout: %v", err)
+	}
+	if _, err := client.ReadInputRegistersTimeout(1, 1, 1, time.Second); err != nil {
+		t.Fatalf("Expected slow input read to succeed with a longer timeout: %v", err)
+	}
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Fatalf("Expected fast read to succeed with the client timeout: %v", err)
+	}
+}
+
+// TestConnectionPoolRecycle tests that a repeatedly failing connection is replaced on Put
+func TestConnectionPoolRecycle(t *testing.T) {
+	server := NewMockServer()
+	silent := false
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if silent {
+			return []byte{}
+		}
+		return nil
+	}
+	address := server.start(t)
+
+	pool, err := NewConnectionPoolWithConfig(PoolConfig{
+		Address:              address,
+		MaxConnections:       1,
+		Timeout:              50 * time.Millisecond,
+		MaxConsecutiveErrors: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	client, _ := pool.Get()
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Fatalf("Initial read failed: %v", err)
+	}
+
+	// One failure stays below the threshold
+	server.mutex.Lock()
+	silent = true
+	server.mutex.Unlock()
+	client.ReadHoldingRegisters(1, 0, 1)
+	pool.Put(client)
+
+	same, _ := pool.Get()
+	if same != client {
+		t.Fatal("Expected connection below the error threshold to be reused")
+	}
+
+	same.ReadHoldingRegisters(1, 0, 1)
+	if same.ConsecutiveErrors() != 2 {
+		t.Errorf("Expected 2 consecutive errors, got %d", same.ConsecutiveErrors())
+	}
+	pool.Put(same)
+
+	server.mutex.Lock()
+	silent = false
+	server.mutex.Unlock()
+
+	replacement, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Failed to get replacement: %v", err)
+	}
+	if replacement == client {
+		t.Fatal("Expected failing connection to be recycled")
+	}
+	if _, err := replacement.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Read on replacement failed: %v", err)
+	}
+	pool.Put(replacement)
+
+	if n := len(pool.Stats().Connections); n != 1 {
+		t.Errorf("Expected pool to still own 1 connection, got %d", n)
+	}
+}
+
+// TestConnectionPoolKeepAlive tests probing idle connections and evicting dead ones
+func TestConnectionPoolKeepAlive(t *testing.T) {
+	server := NewMockServer()
+	address := server.start(t)
+
+	pool, err := NewConnectionPoolWithConfig(PoolConfig{
+		Address:           address,
+		MaxConnections:    2,
+		Timeout:           time.Second,
+		KeepAliveInterval: 10 * time.Millisecond,
+		KeepAliveSlaveID:  9,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	checkedOut, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+
+	// Only the idle connection is probed
+	waitFor(t, func() bool {
+		for _, conn := range pool.Stats().Connections {
+			if conn.LastSlaveID == 9 {
+				return true
+			}
+		}
+		return false
+	})
+	if checkedOut.LastSlaveID() != 0 {
+		t.Error("Expected checked out connection not to be probed")
+	}
+	pool.Put(checkedOut)
+
+	// A dropped connection is replaced by the next probe
+	server.dropConnections()
+	waitFor(t, func() bool {
+		clients := pool.Stats().Connections
+		return len(clients) == 2 && len(pool.pool) == 2 && !containsClient(pool, checkedOut)
+	})
+
+	client, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Read on kept-alive connection failed: %v", err)
+	}
+	pool.Put(client)
+}
+
+// TestConnectionPoolReconnectBackoff tests lazy background redials
+func TestConnectionPoolReconnectBackoff(t *testing.T) {
+	server := NewMockServer()
+	address := server.start(t)
+
+	pool, err := NewConnectionPoolWithConfig(PoolConfig{
+		Address:           address,
+		MaxConnections:    3,
+		Timeout:           time.Second,
+		KeepAliveInterval: 5 * time.Millisecond,
+		ReconnectBackoff:  50 * time.Millisecond,
+		ReconnectJitter:   20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	dropped := time.Now()
+	server.dropConnections()
+
+	// Failed connections leave the pool until their redial is due
+	waitFor(t, func() bool { return len(pool.Stats().Connections) < 3 })
+	waitFor(t, func() bool {
+		stats := pool.Stats()
+		return len(stats.Connections) == 3 && stats.Idle == 3
+	})
+	if elapsed := time.Since(dropped); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected redials to wait for the backoff, pool recovered in %v", elapsed)
+	}
+
+	client, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Read on redialed connection failed: %v", err)
+	}
+	pool.Put(client)
+}
+
+// TestConnectionPoolGetHealthy tests that dead connections are replaced
+// before being handed out
+func TestConnectionPoolGetHealthy(t *testing.T) {
+	server := NewMockServer()
+	address := server.start(t)
+
+	pool, err := NewConnectionPool(address, 2, time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	server.dropConnections()
+
+	client, err := pool.GetHealthy(context.Background())
+	if err != nil {
+		t.Fatalf("GetHealthy failed: %v", err)
+	}
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Read on healthy connection failed: %v", err)
+	}
+
+	// The pool still owns both connections
+	if stats := pool.Stats(); len(stats.Connections) != 2 {
+		t.Errorf("Expected 2 connections, got %d", len(stats.Connections))
+	}
+
+	// With the other connection checked out, ctx bounds the wait
+	other, err := pool.GetHealthy(context.Background())
+	if err != nil {
+		t.Fatalf("GetHealthy failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.GetHealthy(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	pool.Put(other)
+	pool.Put(client)
+}
+
+// TestConnectionPoolSlaveTimeouts tests per-slave timeouts on pooled clients
+func TestConnectionPoolSlaveTimeouts(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if unitID == 9 {
+			time.Sleep(150 * time.Millisecond) // Slow legacy meter
+		}
+		return nil
+	}
+	address := server.start(t)
+
+	pool, err := NewConnectionPoolWithConfig(PoolConfig{
+		Address:        address,
+		MaxConnections: 1,
+		Timeout:        50 * time.Millisecond,
+		SlaveTimeouts:  map[byte]time.Duration{9: time.Second},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	client, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	defer pool.Put(client)
+
+	if _, err := client.ReadHoldingRegisters(9, 0, 1); err != nil {
+		t.Errorf("Expected slow slave to use its longer timeout, got %v", err)
+	}
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Read from fast slave failed: %v", err)
+	}
+	if _, err := client.ReadHoldingRegistersTimeout(9, 0, 1, 20*time.Millisecond); err == nil {
+		t.Error("Expected per-call timeout to override the slave timeout")
+	}
+}
+
+// TestConnectionPoolWarmUp tests probing connections at pool creation
+func TestConnectionPoolWarmUp(t *testing.T) {
+	var probed []uint16
+	var mutex sync.Mutex
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		mutex.Lock()
+		defer mutex.Unlock()
+		probed = append(probed, binary.BigEndian.Uint16(pdu[1:3]))
+		// An exception still proves the device speaks Modbus
+		return []byte{pdu[0] | 0x80, ExceptionIllegalDataAddress}
+	}
+
+	pool, err := NewConnectionPoolWithConfig(PoolConfig{
+		Address:        server.start(t),
+		MaxConnections: 2,
+		Timeout:        time.Second,
+		ProbeAddress:   100,
+		WarmUp:         true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	pool.Close()
+
+	mutex.Lock()
+	if len(probed) != 2 || probed[0] != 100 || probed[1] != 100 {
+		t.Errorf("Expected two probes of register 100, got %v", probed)
+	}
+	mutex.Unlock()
+
+	// A listener that answers with something other than Modbus
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 260)
+				conn.Read(buf)
+				conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+			}()
+		}
+	}()
+
+	config := PoolConfig{
+		Address:        listener.Addr().String(),
+		MaxConnections: 2,
+		Timeout:        time.Second,
+	}
+	pool, err = NewConnectionPoolWithConfig(config)
+	if err != nil {
+		t.Fatalf("Expected pool without warm-up to connect, got %v", err)
+	}
+	pool.Close()
+
+	config.WarmUp = true
+	if _, err := NewConnectionPoolWithConfig(config); err == nil || !strings.Contains(err.Error(), "warm-up") {
+		t.Errorf("Expected warm-up failure, got %v", err)
+	}
+}
+
+// TestConnectionPoolClose tests Get and Put after the pool is closed
+func TestConnectionPoolClose(t *testing.T) {
+	address := NewMockServer().start(t)
+
+	pool, err := NewConnectionPool(address, 1, time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	client, _ := pool.Get()
+	pool.Close()
+	pool.Close() // Closing twice is harmless
+
+	if _, err := pool.Get(); err == nil {
+		t.Error("Expected Get to fail on a closed pool")
+	}
+	pool.Put(client) // Must not panic
+	if client.Connected() {
+		t.Error("Expected connection returned after Close to be closed")
+	}
+}
+
+// containsClient reports whether client is still owned by pool
+func containsClient(pool *ConnectionPool, client *Client) bool {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	for _, c := range pool.clients {
+		if c == client {
+			return true
+		}
+	}
+	return false
+}
+
+// waitFor polls condition until it holds or a second has passed
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestPingAndConnected tests health checking of a client
+func TestPingAndConnected(t *testing.T) {
+	server := NewMockServer()
+	var probed uint16
+	silent := false
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if silent {
+			return []byte{}
+		}
+		probed = binary.BigEndian.Uint16(pdu[1:3])
+		if probed == 9 {
+			return []byte{pdu[0] | 0x80, ExceptionIllegalDataAddress}
+		}
+		return nil
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout:      50 * time.Millisecond,
+		ProbeAddress: 9,
+	})
+
+	if !client.Connected() {
+		t.Error("Expected new client to report connected")
+	}
+	if err := client.Ping(1); err != nil {
+		t.Errorf("Expected exception response to count as alive, got %v", err)
+	}
+	if probed != 9 {
+		t.Errorf("Expected probe at address 9, got %d", probed)
+	}
+
+	server.mutex.Lock()
+	silent = true
+	server.mutex.Unlock()
+	if err := client.Ping(1); err == nil {
+		t.Error("Expected Ping to fail against a silent device")
+	}
+	if client.Connected() {
+		t.Error("Expected Connected to be false after a transport error")
+	}
+
+	client.Close()
+	if client.Connected() {
+		t.Error("Expected Connected to be false after Close")
+	}
+}
+
+// TestOnReconnect tests that the reconnect hook runs before the retried request
+func TestOnReconnect(t *testing.T) {
+	server := NewMockServer()
+	var hookCalls int
+	client := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout:       time.Second,
+		AutoReconnect: true,
+		OnReconnect: func(c *Client) error {
+			hookCalls++
+			// Re-run the device setup on the new connection
+			return c.WriteSingleRegister(1, 0, 0xBEEF)
+		},
+	})
+
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Fatalf("Initial read failed: %v", err)
+	}
+	if hookCalls != 0 {
+		t.Fatalf("Expected no hook calls before a reconnect, got %d", hookCalls)
+	}
+
+	server.dropConnections()
+
+	registers, err := client.ReadHoldingRegisters(1, 0, 1)
+	if err != nil {
+		t.Fatalf("Expected read to succeed after reconnect, got %v", err)
+	}
+	if hookCalls != 1 {
+		t.Errorf("Expected 1 hook call, got %d", hookCalls)
+	}
+	if registers[0] != 0xBEEF {
+		t.Errorf("Expected hook write to precede the retried read, got 0x%04X", registers[0])
+	}
+}
+
+// TestOnReconnectError tests that a failing reconnect hook fails the request
+func TestOnReconnectError(t *testing.T) {
+	server := NewMockServer()
+	hookErr := errors.New("unlock rejected")
+	client := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout:       time.Second,
+		AutoReconnect: true,
+		OnReconnect: func(c *Client) error {
+			return hookErr
+		},
+	})
+
+	server.dropConnections()
+
+	_, err := client.ReadHoldingRegisters(1, 0, 1)
+	if !errors.Is(err, hookErr) {
+		t.Errorf("Expected hook error to propagate, got %v", err)
+	}
+}
+
+// TestOnReconnectFlapping tests that requests failing inside the reconnect
+// hook do not reconnect and run the hook again
+func TestOnReconnectFlapping(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if unitID == 7 {
+			return []byte{} // Never answer the hook's request
+		}
+		return nil
+	}
+	var hookCalls int
+	client := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout:       50 * time.Millisecond,
+		AutoReconnect: true,
+		OnReconnect: func(c *Client) error {
+			hookCalls++
+			if hookCalls > 3 {
+				return errors.New("hook recursed")
+			}
+			_, err := c.ReadHoldingRegisters(7, 0, 1)
+			return err
+		},
+	})
+
+	err := client.Reconnect()
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected the hook's timeout, got %v", err)
+	}
+	if hookCalls != 1 {
+		t.Errorf("Expected 1 hook call with AutoReconnect, got %d", hookCalls)
+	}
+
+	hookCalls = 0
+	client.retryPolicy = &RetryPolicy{MaxAttempts: 5, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	server.dropConnections()
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected the hook's timeout, got %v", err)
+	}
+	if hookCalls != 1 {
+		t.Errorf("Expected 1 hook call with a retry policy, got %d", hookCalls)
+	}
+}
+
+// TestReadWithStaleDetection tests flagging a register that stops changing
+func TestReadWithStaleDetection(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	setValue := func(value uint16) {
+		server.mutex.Lock()
+		server.registers[2] = value
+		server.mutex.Unlock()
+	}
+
+	steps := []struct {
+		value uint16
+		stale bool
+	}{
+		{10, false},
+		{10, false},
+		{10, false},
+		{10, true}, // Fourth identical read is past the threshold of 3
+		{10, true},
+		{11, false}, // A change resets the count
+		{11, false},
+	}
+
+	setValue(10)
+	for i, step := range steps {
+		setValue(step.value)
+		value, stale, err := client.ReadWithStaleDetection(1, 2, 3)
+		if err != nil {
+			t.Fatalf("Read %d failed: %v", i, err)
+		}
+		if value != step.value {
+			t.Errorf("Read %d: expected value %d, got %d", i, step.value, value)
+		}
+		if stale != step.stale {
+			t.Errorf("Read %d: expected stale=%v, got %v", i, step.stale, stale)
+		}
+	}
+
+	// Tracking is per register
+	if _, stale, _ := client.ReadWithStaleDetection(1, 3, 0); !stale {
+		t.Error("Expected threshold 0 to flag the first read of a new register")
+	}
+	if _, stale, _ := client.ReadWithStaleDetection(2, 2, 1); stale {
+		t.Error("Expected a different slave to be tracked separately")
+	}
+}
+
+// fifoResponse builds a FIFO queue response PDU holding values, with the
+// FIFO count field set to count
+func fifoResponse(count int, values ...uint16) []byte {
+	response := []byte{FuncCodeReadFIFOQueue, 0, 0, byte(count >> 8), byte(count)}
+	for _, value := range values {
+		response = append(response, byte(value>>8), byte(value))
+	}
+	binary.BigEndian.PutUint16(response[1:3], uint16(len(response)-3))
+	return response
+}
+
+// TestReadFIFOQueue tests reading a FIFO queue and rejecting oversized counts
+func TestReadFIFOQueue(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	tooMany := make([]uint16, 32)
+
+	tests := []struct {
+		name      string
+		pointer   uint16
+		response  []byte
+		expected  []uint16
+		expectErr bool
+	}{
+		{"three values", 0x04DE, fifoResponse(3, 0x01B8, 0x1284, 0x0042), []uint16{0x01B8, 0x1284, 0x0042}, false},
+		{"empty queue", 0x0100, fifoResponse(0), []uint16{}, false},
+		{"count above 31", 0x0200, fifoResponse(32, tooMany...), nil, true},
+		{"count mismatch", 0x0300, fifoResponse(2, 0x0001), nil, true},
+		{"truncated", 0x0400, []byte{FuncCodeReadFIFOQueue, 0x00}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.mutex.Lock()
+			server.handler = func(unitID byte, pdu []byte) []byte {
+				if pdu[0] != FuncCodeReadFIFOQueue {
+					return nil
+				}
+				if pointer := binary.BigEndian.Uint16(pdu[1:3]); pointer != tt.pointer {
+					t.Errorf("Expected FIFO pointer 0x%04X, got 0x%04X", tt.pointer, pointer)
+				}
+				return tt.response
+			}
+			server.mutex.Unlock()
+
+			values, err := client.ReadFIFOQueue(1, tt.pointer)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, got values %v", values)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadFIFOQueue failed: %v", err)
+			}
+			if len(values) != len(tt.expected) {
+				t.Fatalf("Expected %d values, got %d", len(tt.expected), len(values))
+			}
+			for i := range values {
+				if values[i] != tt.expected[i] {
+					t.Errorf("Value %d: expected 0x%04X, got 0x%04X", i, tt.expected[i], values[i])
+				}
+			}
+		})
+	}
+}
+
+// TestInt16 tests reading and writing signed register values
+func TestInt16(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	values := []int16{-40, 0, 125, -32768, 32767}
+	if err := client.WriteInt16(1, 10, values); err != nil {
+		t.Fatalf("WriteInt16 failed: %v", err)
+	}
+
+	server.mutex.Lock()
+	raw := server.registers[10]
+	server.mutex.Unlock()
+	if raw != 0xFFD8 {
+		t.Errorf("Expected raw register 0xFFD8 for -40, got 0x%04X", raw)
+	}
+
+	read, err := client.ReadInt16(1, 10, uint16(len(values)))
+	if err != nil {
+		t.Fatalf("ReadInt16 failed: %v", err)
+	}
+	for i := range values {
+		if read[i] != values[i] {
+			t.Errorf("Value %d: expected %d, got %d", i, values[i], read[i])
+		}
+	}
+
+	if err := client.WriteInt16Value(1, 20, -1); err != nil {
+		t.Fatalf("WriteInt16Value failed: %v", err)
+	}
+	value, err := client.ReadInt16Value(1, 20)
+	if err != nil {
+		t.Fatalf("ReadInt16Value failed: %v", err)
+	}
+	if value != -1 {
+		t.Errorf("Expected -1, got %d", value)
+	}
+}
+
+// selfSignedCertificate generates a throwaway certificate for 127.0.0.1
+func selfSignedCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "modbus test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// TestTLSClient tests connecting to a device over TLS
+func TestTLSClient(t *testing.T) {
+	cert := selfSignedCertificate(t)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := NewMockServer()
+	server.registers[0] = 802
+	address := server.serve(t, listener)
+
+	roots := x509.NewCertPool()
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	roots.AddCert(parsed)
+
+	client, err := NewClient(ClientConfig{
+		Address:   address,
+		Timeout:   time.Second,
+		TLSConfig: &tls.Config{RootCAs: roots},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect over TLS: %v", err)
+	}
+	defer client.Close()
+
+	registers, err := client.ReadHoldingRegisters(1, 0, 1)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if registers[0] != 802 {
+		t.Errorf("Expected 802, got %d", registers[0])
+	}
+
+	// Without the root the handshake must fail verification
+	if _, err := NewClient(ClientConfig{
+		Address:   address,
+		Timeout:   time.Second,
+		TLSConfig: &tls.Config{},
+	}); err == nil {
+		t.Error("Expected certificate verification error")
+	}
+}
+
+// TestExceptionCodes tests exception code names and ModbusError predicates
+func TestExceptionCodes(t *testing.T) {
+	tests := []struct {
+		code byte
+		name string
+	}{
+		{ExceptionIllegalFunction, "illegal function"},
+		{ExceptionIllegalDataAddress, "illegal data address"},
+		{ExceptionIllegalDataValue, "illegal data value"},
+		{ExceptionSlaveDeviceFailure, "slave device failure"},
+		{ExceptionAcknowledge, "acknowledge"},
+		{ExceptionSlaveDeviceBusy, "slave device busy"},
+		{ExceptionNegativeAcknowledge, "negative acknowledge"},
+		{ExceptionMemoryParityError, "memory parity error"},
+		{0x09, "unknown exception 0x09"},
+		{ExceptionGatewayPathUnavailable, "gateway path unavailable"},
+		{ExceptionGatewayTargetFailed, "gateway target device failed to respond"},
+	}
+
+	for _, tt := range tests {
+		err := &ModbusError{FunctionCode: 0x03, ExceptionCode: tt.code}
+		if got := err.Code().String(); got != tt.name {
+			t.Errorf("Code 0x%02X: expected %q, got %q", tt.code, tt.name, got)
+		}
+	}
+
+	predicates := []struct {
+		name  string
+		check func(*ModbusError) bool
+		code  byte
+	}{
+		{"IsIllegalFunction", (*ModbusError).IsIllegalFunction, ExceptionIllegalFunction},
+		{"IsIllegalAddress", (*ModbusError).IsIllegalAddress, ExceptionIllegalDataAddress},
+		{"IsIllegalDataValue", (*ModbusError).IsIllegalDataValue, ExceptionIllegalDataValue},
+		{"IsSlaveDeviceFailure", (*ModbusError).IsSlaveDeviceFailure, ExceptionSlaveDeviceFailure},
+		{"IsBusy", (*ModbusError).IsBusy, ExceptionSlaveDeviceBusy},
+		{"IsGatewayPathUnavailable", (*ModbusError).IsGatewayPathUnavailable, ExceptionGatewayPathUnavailable},
+		{"IsGatewayTimeout", (*ModbusError).IsGatewayTimeout, ExceptionGatewayTargetFailed},
+	}
+
+	for _, p := range predicates {
+		for code := byte(0x01); code <= 0x0B; code++ {
+			err := &ModbusError{FunctionCode: 0x03, ExceptionCode: code}
+			if got := p.check(err); got != (code == p.code) {
+				t.Errorf("%s with code 0x%02X: expected %v, got %v", p.name, code, code == p.code, got)
+			}
+		}
+	}
+}
+
+// TestModbusErrorIs tests matching exceptions against sentinel errors
+func TestModbusErrorIs(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	server.mutex.Lock()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		return []byte{pdu[0] | 0x80, ExceptionIllegalDataAddress}
+	}
+	server.mutex.Unlock()
+
+	_, err := client.ReadHoldingRegisters(1, 0, 1)
+	wrapped := fmt.Errorf("polling meter: %w", err)
+
+	if !errors.Is(wrapped, ErrIllegalDataAddress) {
+		t.Errorf("Expected errors.Is to match ErrIllegalDataAddress, got %v", err)
+	}
+	if errors.Is(wrapped, ErrIllegalFunction) {
+		t.Error("Expected errors.Is not to match ErrIllegalFunction")
+	}
+
+	var modbusErr *ModbusError
+	if !errors.As(wrapped, &modbusErr) || modbusErr.FunctionCode != FuncCodeReadHoldingRegisters {
+		t.Errorf("Expected errors.As to extract the exception, got %v", modbusErr)
+	}
+
+	for code, sentinel := range exceptionSentinels {
+		if !errors.Is(&ModbusError{ExceptionCode: code}, sentinel) {
+			t.Errorf("Code 0x%02X does not match its sentinel %v", code, sentinel)
+		}
+	}
+	if errors.Is(&ModbusError{ExceptionCode: 0x09}, ErrIllegalFunction) {
+		t.Error("Expected unknown code to match no sentinel")
+	}
+}
+
+// unitRewriter is a transport that reports every response as coming from
+// unit ID unit, as a misbehaving gateway might
+type unitRewriter struct {
+	*MemoryTransport
+	unit byte
+}
+
+func (u *unitRewriter) Read(p []byte) (int, error) {
+	n, err := u.MemoryTransport.Read(p)
+	if n == 7 {
+		p[6] = u.unit // Header read
+	}
+	return n, err
+}
+
+// TestGatewayUnitIDs tests interleaving unit IDs on one connection
+func TestGatewayUnitIDs(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] == FuncCodeReadHoldingRegisters {
+			return []byte{pdu[0], 2, 0, unitID * 10}
+		}
+		if pdu[0] == FuncCodeReadInputRegisters {
+			return []byte{FuncCodeReadHoldingRegisters, 2, 0, 0} // Wrong function
+		}
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	for i := 0; i < 5; i++ {
+		for _, unitID := range []byte{3, 7} {
+			registers, err := client.ReadHoldingRegisters(unitID, 0, 1)
+			if err != nil {
+				t.Fatalf("Read from unit %d failed: %v", unitID, err)
+			}
+			if registers[0] != uint16(unitID)*10 {
+				t.Errorf("Unit %d: expected %d, got %d", unitID, uint16(unitID)*10, registers[0])
+			}
+		}
+	}
+
+	if _, err := client.ReadInputRegisters(3, 0, 1); err == nil {
+		t.Error("Expected function code mismatch error")
+	}
+	// The mismatched frame was consumed, so the connection is still usable
+	if _, err := client.ReadHoldingRegisters(3, 0, 1); err != nil {
+		t.Errorf("Expected read after mismatch to succeed, got %v", err)
+	}
+
+	rewriter := &unitRewriter{MemoryTransport: NewMemoryTransport(), unit: 9}
+	gateway := NewClientWithTransport(rewriter, ClientConfig{})
+	if _, err := gateway.ReadHoldingRegisters(3, 0, 1); err == nil {
+		t.Error("Expected unit ID mismatch error")
+	}
+}
+
+// lengthRewriter is a MemoryTransport that reports a fixed MBAP length in
+// every response header
+type lengthRewriter struct {
+	*MemoryTransport
+	length uint16
+}
+
+func (l *lengthRewriter) Read(p []byte) (int, error) {
+	n, err := l.MemoryTransport.Read(p)
+	if n == 7 {
+		binary.BigEndian.PutUint16(p[4:6], l.length) // Header read
+	}
+	return n, err
+}
+
+// TestMBAPLength tests rejection of out-of-range response length fields
+func TestMBAPLength(t *testing.T) {
+	for _, length := range []uint16{0, 255, 0xFFFF} {
+		rewriter := &lengthRewriter{MemoryTransport: NewMemoryTransport(), length: length}
+		client := NewClientWithTransport(rewriter, ClientConfig{})
+		_, err := client.ReadHoldingRegisters(1, 0, 1)
+		if err == nil || !strings.Contains(err.Error(), "invalid MBAP length") {
+			t.Errorf("Length %d: expected invalid MBAP length error, got %v", length, err)
+		}
+		if !isTransportError(err) {
+			t.Errorf("Length %d: expected a transport error so the connection is reset, got %v", length, err)
+		}
+	}
+}
+
+// TestMinInterval tests the enforced gap between requests on a connection
+func TestMinInterval(t *testing.T) {
+	var mutex sync.Mutex
+	var finished []time.Time
+	client := NewClientWithTransport(NewMemoryTransport(), ClientConfig{
+		MinInterval: 30 * time.Millisecond,
+		OnTransaction: func(request, response []byte, err error) {
+			mutex.Lock()
+			finished = append(finished, time.Now())
+			mutex.Unlock()
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+			t.Fatalf("Read %d failed: %v", i, err)
+		}
+	}
+	for i := 1; i < len(finished); i++ {
+		if gap := finished[i].Sub(finished[i-1]); gap < 30*time.Millisecond {
+			t.Errorf("Request %d: expected a gap of at least 30ms, got %v", i, gap)
+		}
+	}
+
+	// A cancelled context stops the wait
+	slow := NewClientWithTransport(NewMemoryTransport(), ClientConfig{MinInterval: time.Minute})
+	if _, err := slow.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Fatalf("First read failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err := slow.WaitForRegister(ctx, 1, 0, func(uint16) bool { return true }, time.Millisecond)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// A wait that would pass the deadline fails at once
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = slow.WaitForRegister(ctx, 1, 0, func(uint16) bool { return true }, time.Millisecond)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+}
+
+// truncatingTransport is a MemoryTransport that loses the last drop bytes of
+// every response, like a device that stalls mid-frame
+type truncatingTransport struct {
+	*MemoryTransport
+	drop int
+}
+
+func (tr truncatingTransport) Write(p []byte) (int, error) {
+	n, err := tr.MemoryTransport.Write(p)
+	tr.mutex.Lock()
+	tr.pending = tr.pending[:len(tr.pending)-tr.drop]
+	tr.mutex.Unlock()
+	return n, err
+}
+
+// tricklingTransport is a MemoryTransport that delivers responses one byte
+// per read
+type tricklingTransport struct {
+	*MemoryTransport
+}
+
+func (tr tricklingTransport) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return tr.MemoryTransport.Read(p)
+}
+
+// TestPartialResponse tests reading frames split across reads and reporting
+// how much of a truncated frame arrived
+func TestPartialResponse(t *testing.T) {
+	device := NewMemoryTransport()
+	device.SetRegister(0, 42)
+	trickling := NewClientWithTransport(tricklingTransport{device}, ClientConfig{})
+	registers, err := trickling.ReadHoldingRegisters(1, 0, 1)
+	if err != nil || registers[0] != 42 {
+		t.Errorf("Expected [42] from a trickled response, got %v (err %v)", registers, err)
+	}
+
+	tests := []struct {
+		drop     int
+		expected string
+	}{
+		{2, "failed to read response data: got 2 of 4 bytes"}, // 4 bytes follow the unit ID
+		{9, "failed to read response header: got 2 of 7 bytes"},
+	}
+
+	for _, tt := range tests {
+		client := NewClientWithTransport(truncatingTransport{NewMemoryTransport(), tt.drop}, ClientConfig{})
+		_, err := client.ReadHoldingRegisters(1, 0, 1)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Drop %d: expected %q, got %v", tt.drop, tt.expected, err)
+		}
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("Drop %d: expected ErrTimeout, got %v", tt.drop, err)
+		}
+	}
+}
+
+// zeroTransactionID is a MemoryTransport that answers every request with
+// transaction ID 0, like some non-compliant gateways
+type zeroTransactionID struct {
+	*MemoryTransport
+}
+
+func (z zeroTransactionID) Read(p []byte) (int, error) {
+	n, err := z.MemoryTransport.Read(p)
+	if n == 7 {
+		p[0], p[1] = 0, 0 // Header read
+	}
+	return n, err
+}
+
+// TestFlush tests discarding a stale response before the next request
+func TestFlush(t *testing.T) {
+	device := NewMemoryTransport()
+	device.SetRegister(0, 42)
+	client := NewClientWithTransport(device, ClientConfig{})
+
+	// Queue a response nobody is waiting for, as a timed out request leaves
+	stale := []byte{0x12, 0x34, 0x00, 0x00, 0x00, 0x06, 0x01, 0x03, 0x00, 0x00, 0x00, 0x01}
+	if _, err := device.Write(stale); err != nil {
+		t.Fatalf("Failed to queue stale response: %v", err)
+	}
+
+	if err := client.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	registers, err := client.ReadHoldingRegisters(1, 0, 1)
+	if err != nil {
+		t.Fatalf("Read after flush failed: %v", err)
+	}
+	if registers[0] != 42 {
+		t.Errorf("Expected 42, got %d", registers[0])
+	}
+
+	client.Close()
+	if err := client.Flush(); err == nil {
+		t.Error("Expected Flush on a closed connection to fail")
+	}
+}
+
+// TestIgnoreTransactionID tests accepting responses with a wrong transaction ID
+func TestIgnoreTransactionID(t *testing.T) {
+	strict := NewClientWithTransport(zeroTransactionID{NewMemoryTransport()}, ClientConfig{})
+	strict.transactionID = 10
+	if _, err := strict.ReadHoldingRegisters(1, 0, 1); err == nil || !strings.Contains(err.Error(), "transaction ID mismatch") {
+		t.Errorf("Expected transaction ID mismatch by default, got %v", err)
+	}
+
+	// The mismatched frame is read in full, so the stream stays in sync: the
+	// next request wraps round to ID 0 and matches
+	device := NewMemoryTransport()
+	device.SetRegister(0, 42)
+	resync := NewClientWithTransport(zeroTransactionID{device}, ClientConfig{})
+	resync.transactionID = 0xFFFE
+	if _, err := resync.ReadHoldingRegisters(1, 0, 1); err == nil || !strings.Contains(err.Error(), "transaction ID mismatch") {
+		t.Errorf("Expected transaction ID mismatch, got %v", err)
+	}
+	registers, err := resync.ReadHoldingRegisters(1, 0, 1)
+	if err != nil {
+		t.Fatalf("Expected read after mismatch to succeed, got %v", err)
+	}
+	if registers[0] != 42 {
+		t.Errorf("Expected 42, got %d", registers[0])
+	}
+
+	lenient := NewClientWithTransport(zeroTransactionID{device}, ClientConfig{IgnoreTransactionID: true})
+	lenient.transactionID = 10
+	for i := 0; i < 3; i++ {
+		registers, err := lenient.ReadHoldingRegisters(1, 0, 1)
+		if err != nil {
+			t.Fatalf("Expected mismatched transaction ID to be ignored, got %v", err)
+		}
+		if registers[0] != 42 {
+			t.Errorf("Expected 42, got %d", registers[0])
+		}
+	}
+}
+
+// TestProtocolID tests the MBAP protocol identifier written to requests
+func TestProtocolID(t *testing.T) {
+	for _, protocolID := range []uint16{0, 0x1234} {
+		var sent uint16
+		client := NewClientWithTransport(NewMemoryTransport(), ClientConfig{
+			ProtocolID: protocolID,
+			OnTransaction: func(request, response []byte, err error) {
+				sent = binary.BigEndian.Uint16(request[2:4])
+			},
+		})
+		if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		if sent != protocolID {
+			t.Errorf("Expected protocol ID 0x%04X, got 0x%04X", protocolID, sent)
+		}
+	}
+}
+
+// TestWriteMultipleEcho tests verification of the acknowledged write range
+func TestWriteMultipleEcho(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	tests := []struct {
+		name      string
+		ack       func(pdu []byte) []byte
+		expectErr bool
+	}{
+		{"correct", func(pdu []byte) []byte { return nil }, false},
+		{"wrong address", func(pdu []byte) []byte { return []byte{pdu[0], 0x00, 0x11, pdu[3], pdu[4]} }, true},
+		{"wrong quantity", func(pdu []byte) []byte { return []byte{pdu[0], pdu[1], pdu[2], 0x00, 0x01} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.mutex.Lock()
+			server.handler = func(unitID byte, pdu []byte) []byte { return tt.ack(pdu) }
+			server.mutex.Unlock()
+
+			err := client.WriteMultipleRegisters(1, 0x10, []uint16{1, 2, 3})
+			if (err != nil) != tt.expectErr {
+				t.Errorf("WriteMultipleRegisters: expected error=%v, got %v", tt.expectErr, err)
+			}
+			err = client.WriteMultipleCoils(1, 0x10, []bool{true, false, true})
+			if (err != nil) != tt.expectErr {
+				t.Errorf("WriteMultipleCoils: expected error=%v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+// countingDialer records the addresses it dials before delegating to net.Dialer
+type countingDialer struct {
+	mutex     sync.Mutex
+	addresses []string
+}
+
+func (d *countingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	d.mutex.Lock()
+	d.addresses = append(d.addresses, network+" "+address)
+	d.mutex.Unlock()
+	return (&net.Dialer{}).DialContext(ctx, network, address)
+}
+
+// TestCustomDialer tests connecting through a caller-supplied dialer
+func TestCustomDialer(t *testing.T) {
+	server := NewMockServer()
+	dialer := &countingDialer{}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second, Dialer: dialer})
+
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if err := client.Reconnect(); err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+
+	dialer.mutex.Lock()
+	defer dialer.mutex.Unlock()
+	if len(dialer.addresses) != 2 || !strings.HasPrefix(dialer.addresses[0], "tcp 127.0.0.1:") {
+		t.Errorf("Expected two tcp dials through the custom dialer, got %v", dialer.addresses)
+	}
+}
+
+// blockingDialer never connects; it waits for its context to end, or fails
+// at once when fail is set
+type blockingDialer struct {
+	fail bool
+}
+
+func (d blockingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if d.fail {
+		return nil, errors.New("connection refused")
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// TestNewClientContext tests cancelling connection establishment
+func TestNewClientContext(t *testing.T) {
+	server := NewMockServer()
+	client, err := NewClientContext(context.Background(), ClientConfig{Address: server.start(t), Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewClientContext failed: %v", err)
+	}
+	client.Close()
+
+	tests := []struct {
+		name   string
+		config ClientConfig
+	}{
+		{"slow dial", ClientConfig{Timeout: time.Minute, Dialer: blockingDialer{}}},
+		{"retry delay", ClientConfig{Timeout: time.Minute, Dialer: blockingDialer{fail: true}, DialRetries: 3, DialRetryDelay: time.Minute}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+
+			start := time.Now()
+			_, err := NewClientContext(ctx, tt.config)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected context.Canceled, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("Expected cancellation to end the dial promptly, took %v", elapsed)
+			}
+		})
+	}
+}
+
+// TestUnixSocket tests connecting over a Unix domain socket
+func TestUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "modbus.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	server := NewMockServer()
+	server.registers[0] = 42
+	server.serve(t, listener)
+
+	client, err := NewClient(ClientConfig{Address: path, Network: "unix", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Failed to connect over Unix socket: %v", err)
+	}
+	defer client.Close()
+
+	registers, err := client.ReadHoldingRegisters(1, 0, 1)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegisters failed: %v", err)
+	}
+	if registers[0] != 42 {
+		t.Errorf("Expected 42, got %d", registers[0])
+	}
+}
+
+// TestPreferSingleWrites tests routing single-value writes to 0x05 and 0x06
+func TestPreferSingleWrites(t *testing.T) {
+	server := NewMockServer()
+	var functions []byte
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		functions = append(functions, pdu[0])
+		return nil
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second, PreferSingleWrites: true})
+
+	results := client.ExecuteBatch([]BatchOperation{
+		{Operation: "write_coils", SlaveID: 1, Address: 4, Values: []bool{true}},
+		{Operation: "write_registers", SlaveID: 1, Address: 8, Values: []uint16{77}},
+		{Operation: "write_registers", SlaveID: 1, Address: 9, Values: []uint16{1, 2}},
+	})
+	for i, result := range results {
+		if result.Error != nil {
+			t.Errorf("Operation %d failed: %v", i, result.Error)
+		}
+	}
+
+	// Direct writes take the same shortcut as batch writes
+	if err := client.WriteMultipleCoils(1, 5, []bool{true}); err != nil {
+		t.Errorf("WriteMultipleCoils failed: %v", err)
+	}
+	if err := client.WriteMultipleRegisters(1, 10, []uint16{88}); err != nil {
+		t.Errorf("WriteMultipleRegisters failed: %v", err)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	expected := []byte{
+		FuncCodeWriteSingleCoil, FuncCodeWriteSingleRegister, FuncCodeWriteMultipleRegisters,
+		FuncCodeWriteSingleCoil, FuncCodeWriteSingleRegister,
+	}
+	if string(functions) != string(expected) {
+		t.Errorf("Expected function codes % X, got % X", expected, functions)
+	}
+	if !server.coils[4] || server.registers[8] != 77 {
+		t.Errorf("Expected coil 4 on and register 8 = 77, got %v and %d", server.coils[4], server.registers[8])
+	}
+}
+
+// TestTransactionIDSeed tests random initial IDs and continuity across reconnects
+func TestTransactionIDSeed(t *testing.T) {
+	// Eight clients all starting from the same ID is practically impossible
+	seen := make(map[uint16]bool)
+	for i := 0; i < 8; i++ {
+		seen[NewClientWithTransport(NewMemoryTransport(), ClientConfig{}).transactionID] = true
+	}
+	if len(seen) == 1 {
+		t.Error("Expected clients to start from different transaction IDs")
+	}
+
+	server := NewMockServer()
+	var ids []uint16
+	client := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout: time.Second,
+		OnTransaction: func(request, response []byte, err error) {
+			ids = append(ids, binary.BigEndian.Uint16(request[0:2]))
+		},
+	})
+
+	client.ReadHoldingRegisters(1, 0, 1)
+	if err := client.Reconnect(); err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+	client.ReadHoldingRegisters(1, 0, 1)
+
+	if len(ids) != 2 || ids[1] != ids[0]+1 {
+		t.Errorf("Expected consecutive transaction IDs across reconnect, got %v", ids)
+	}
+}
+
+// TestExecuteBatchContext tests cancelling a batch part way through
+func TestExecuteBatchContext(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel as soon as the second operation reaches the device
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if binary.BigEndian.Uint16(pdu[1:3]) == 2 {
+			cancel()
+		}
+		return nil
+	}
+
+	// The reads leave gaps so they are not merged into one request
+	operations := make([]BatchOperation, 4)
+	for i := range operations {
+		operations[i] = BatchOperation{Operation: "read_holding", SlaveID: 1, Address: uint16(2 * i), Quantity: 1}
+	}
+
+	results := client.ExecuteBatchContext(ctx, operations)
+	for i, result := range results[:2] {
+		if result.Error != nil {
+			t.Errorf("Operation %d: expected success, got %v", i, result.Error)
+		}
+	}
+	for i, result := range results[2:] {
+		if !errors.Is(result.Error, context.Canceled) {
+			t.Errorf("Operation %d: expected context.Canceled, got %v", i+2, result.Error)
+		}
+		if result.Operation != "read_holding" {
+			t.Errorf("Operation %d: expected operation name to be kept, got %q", i+2, result.Operation)
+		}
+	}
+}
+
+// TestExecuteBatchDuration tests per-operation round trip timing
+func TestExecuteBatchDuration(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	// The device is slow to answer reads of address 2
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if binary.BigEndian.Uint16(pdu[1:3]) == 2 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return nil
+	}
+
+	results := client.ExecuteBatch([]BatchOperation{
+		{Operation: "read_holding", SlaveID: 1, Address: 0, Quantity: 1},
+		{Operation: "read_holding", SlaveID: 1, Address: 2, Quantity: 1},
+		{Operation: "write_registers", SlaveID: 1, Address: 0, Values: "invalid"},
+	})
+
+	if results[0].Duration <= 0 {
+		t.Errorf("Expected a positive duration, got %v", results[0].Duration)
+	}
+	if results[1].Duration < 20*time.Millisecond {
+		t.Errorf("Expected duration of at least 20ms for the slow read, got %v", results[1].Duration)
+	}
+	if results[2].Error == nil || results[2].Duration != 0 {
+		t.Errorf("Expected an unsent operation to fail with zero duration, got %v, %v", results[2].Error, results[2].Duration)
+	}
+}
+
+// TestReadInto tests decoding reads into caller-supplied slices
+func TestReadInto(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	server.registers[20] = 11
+	server.registers[21] = 22
+	server.coils[2] = true
+
+	registers := make([]uint16, 4)
+	registers[3] = 99
+	n, err := client.ReadHoldingRegistersInto(1, 20, 2, registers)
+	if err != nil {
+		t.Fatalf("ReadHoldingRegistersInto failed: %v", err)
+	}
+	if n != 2 || registers[0] != 11 || registers[1] != 22 || registers[3] != 99 {
+		t.Errorf("Expected 2 values [11 22 _ 99], got %d values %v", n, registers)
+	}
+
+	n, err = client.ReadInputRegistersInto(1, 21, 1, registers)
+	if err != nil || n != 1 || registers[0] != 22 {
+		t.Errorf("Expected input register 22, got %d values %v (err %v)", n, registers, err)
+	}
+
+	coils := make([]bool, 8)
+	n, err = client.ReadCoilsInto(1, 0, 3, coils)
+	if err != nil {
+		t.Fatalf("ReadCoilsInto failed: %v", err)
+	}
+	if n != 3 || coils[0] || coils[1] || !coils[2] {
+		t.Errorf("Expected 3 coils [false false true], got %d values %v", n, coils)
+	}
+
+	if _, err := client.ReadHoldingRegistersInto(1, 0, 5, registers); err == nil {
+		t.Error("Expected error for destination smaller than quantity")
+	}
+	if _, err := client.ReadCoilsInto(1, 0, 9, coils); err == nil {
+		t.Error("Expected error for coil destination smaller than quantity")
+	}
+}
+
+// TestReadRawBytes tests reading the on-wire register payload
+func TestReadRawBytes(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	server.registers[30] = 0x1234
+	server.registers[31] = 0xABCD
+
+	raw, err := client.ReadRawBytes(1, 30, 2)
+	if err != nil {
+		t.Fatalf("ReadRawBytes failed: %v", err)
+	}
+	expected := []byte{0x12, 0x34, 0xAB, 0xCD}
+	if !bytes.Equal(raw, expected) {
+		t.Errorf("Expected % X, got % X", expected, raw)
+	}
+
+	if _, err := client.ReadRawBytes(1, 0, 0); err == nil {
+		t.Error("Expected error for zero quantity")
+	}
+}
+
+// TestWriteMultipleRegistersBytes tests writing registers from raw bytes
+func TestWriteMultipleRegistersBytes(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	data := []byte{0x12, 0x34, 0xAB, 0xCD}
+	if err := client.WriteMultipleRegistersBytes(1, 40, data); err != nil {
+		t.Fatalf("WriteMultipleRegistersBytes failed: %v", err)
+	}
+	if server.registers[40] != 0x1234 || server.registers[41] != 0xABCD {
+		t.Errorf("Expected [0x1234 0xABCD], got [0x%04X 0x%04X]", server.registers[40], server.registers[41])
+	}
+
+	raw, err := client.ReadRawBytes(1, 40, 2)
+	if err != nil || !bytes.Equal(raw, data) {
+		t.Errorf("Expected ReadRawBytes to return % X, got % X (err %v)", data, raw, err)
+	}
+
+	if err := client.WriteMultipleRegistersBytes(1, 40, []byte{1, 2, 3}); err == nil {
+		t.Error("Expected error for odd data length")
+	}
+	if err := client.WriteMultipleRegistersBytes(1, 40, nil); err == nil {
+		t.Error("Expected error for empty data")
+	}
+}
+
+// TestWriteMultipleCoilsBytes tests sending packed coils with chosen padding bits
+func TestWriteMultipleCoilsBytes(t *testing.T) {
+	var sent []byte
+	device := NewMemoryTransport()
+	client := NewClientWithTransport(device, ClientConfig{
+		OnTransaction: func(request, response []byte, err error) { sent = request },
+	})
+
+	// 10 coils: 1,0,1,0,0,0,0,0 then 1,1 with the six unused bits set
+	if err := client.WriteMultipleCoilsBytes(1, 20, 10, []byte{0x05, 0xFF}); err != nil {
+		t.Fatalf("WriteMultipleCoilsBytes failed: %v", err)
+	}
+	if !bytes.Equal(sent[len(sent)-3:], []byte{0x02, 0x05, 0xFF}) {
+		t.Errorf("Expected byte count and coil bytes 02 05 FF, got % X", sent[len(sent)-3:])
+	}
+	for i, want := range []bool{true, false, true, false, false, false, false, false, true, true, false} {
+		if got := device.Coil(20 + uint16(i)); got != want {
+			t.Errorf("Coil %d: expected %v, got %v", 20+i, want, got)
+		}
+	}
+
+	tests := []struct {
+		quantity uint16
+		packed   []byte
+	}{
+		{0, nil},
+		{10, []byte{0x05}},
+		{8, []byte{0x05, 0x00}},
+		{1969, make([]byte, 247)},
+	}
+	for _, tt := range tests {
+		if err := client.WriteMultipleCoilsBytes(1, 0, tt.quantity, tt.packed); err == nil {
+			t.Errorf("Quantity %d with %d bytes: expected error", tt.quantity, len(tt.packed))
+		}
+	}
+}
+
+// TestFloat32s tests bulk float reads and writes spanning several requests
+func TestFloat32s(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	values := make([]float32, 70) // 140 registers, more than one request
+	for i := range values {
+		values[i] = float32(i) * 1.5
+	}
+
+	for _, order := range []ByteOrder{ByteOrderBig, ByteOrderLittle} {
+		if err := client.WriteFloat32s(1, 200, values, order); err != nil {
+			t.Fatalf("WriteFloat32s(%s) failed: %v", order, err)
+		}
+		read, err := client.ReadFloat32s(1, 200, len(values), order)
+		if err != nil {
+			t.Fatalf("ReadFloat32s(%s) failed: %v", order, err)
+		}
+		for i := range values {
+			if read[i] != values[i] {
+				t.Errorf("%s value %d: expected %v, got %v", order, i, values[i], read[i])
+				break
+			}
+		}
+		single, err := client.ReadFloat32(1, 200+2*69, order)
+		if err != nil || single != values[69] {
+			t.Errorf("%s: expected ReadFloat32 to agree with bulk write, got %v (err %v)", order, single, err)
+		}
+	}
+
+	if _, err := client.ReadFloat32s(1, 65535, 1, ByteOrderBig); err == nil {
+		t.Error("Expected error for float past the end of the address space")
+	}
+	if err := client.WriteFloat32s(1, 0, values, "middle"); err == nil {
+		t.Error("Expected error for invalid byte order")
+	}
+}
+
+// TestInputFloat32 tests float reads from input registers
+func TestInputFloat32(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] != FuncCodeReadInputRegisters {
+			return nil
+		}
+		// 1.5 is 0x3FC00000; even input registers hold 0x3FC0, odd ones 0x0000
+		address := int(binary.BigEndian.Uint16(pdu[1:3]))
+		quantity := int(binary.BigEndian.Uint16(pdu[3:5]))
+		response := []byte{FuncCodeReadInputRegisters, byte(2 * quantity)}
+		for i := 0; i < quantity; i++ {
+			if (address+i)%2 == 0 {
+				response = append(response, 0x3F, 0xC0)
+			} else {
+				response = append(response, 0x00, 0x00)
+			}
+		}
+		return response
+	}
+	client := newTestClient(t, server)
+
+	value, err := client.ReadInputFloat32(1, 10, ByteOrderBig)
+	if err != nil {
+		t.Fatalf("ReadInputFloat32 failed: %v", err)
+	}
+	if value != 1.5 {
+		t.Errorf("Expected 1.5, got %v", value)
+	}
+
+	values, err := client.ReadInputFloat32s(1, 0, 70, ByteOrderBig) // More than one request
+	if err != nil {
+		t.Fatalf("ReadInputFloat32s failed: %v", err)
+	}
+	for i, value := range values {
+		if value != 1.5 {
+			t.Errorf("Value %d: expected 1.5, got %v", i, value)
+			break
+		}
+	}
+
+	// The holding registers are untouched by the handler and read as zero
+	if value, err := client.ReadFloat32(1, 10, ByteOrderBig); err != nil || value != 0 {
+		t.Errorf("Expected holding register float 0, got %v (err %v)", value, err)
+	}
+}
+
+// TestBatchReadDiscrete tests discrete input reads alongside coil reads
+func TestBatchReadDiscrete(t *testing.T) {
+	var mutex sync.Mutex
+	var funcCodes []byte
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		mutex.Lock()
+		defer mutex.Unlock()
+		funcCodes = append(funcCodes, pdu[0])
+		// Coils read as all off and discrete inputs as all on, so the
+		// values show which table answered
+		switch pdu[0] {
+		case FuncCodeReadCoils:
+			return []byte{pdu[0], 1, 0x00}
+		case FuncCodeReadDiscreteInputs:
+			return []byte{pdu[0], 1, 0xFF}
+		}
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	results := client.ExecuteBatch([]BatchOperation{
+		{Operation: "read_discrete", SlaveID: 1, Address: 0, Quantity: 3},
+		{Operation: "read_coils", SlaveID: 1, Address: 0, Quantity: 3},
+		{Operation: "read_discrete", SlaveID: 1, Address: 3, Quantity: 2},
+		{Operation: "read_discrete", SlaveID: 1, Address: 0, Quantity: 2001},
+	})
+
+	expected := [][]bool{{true, true, true}, {false, false, false}, {true, true}}
+	for i, want := range expected {
+		if results[i].Error != nil {
+			t.Fatalf("Operation %d failed: %v", i, results[i].Error)
+		}
+		got, ok := results[i].Values.([]bool)
+		if !ok || len(got) != len(want) {
+			t.Fatalf("Operation %d: expected %v, got %v", i, want, results[i].Values)
+		}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Errorf("Operation %d: expected %v, got %v", i, want, got)
+				break
+			}
+		}
+	}
+	if results[3].Error == nil {
+		t.Error("Expected error for invalid quantity")
+	}
+
+	// One request per valid operation, in order and with the right function
+	mutex.Lock()
+	defer mutex.Unlock()
+	sent := []byte{FuncCodeReadDiscreteInputs, FuncCodeReadCoils, FuncCodeReadDiscreteInputs}
+	if !bytes.Equal(funcCodes, sent) {
+		t.Errorf("Expected function codes % X, got % X", sent, funcCodes)
+	}
+}
+
+// TestBatchCoalescing tests merging runs of adjacent reads into one request
+func TestBatchCoalescing(t *testing.T) {
+	server := NewMockServer()
+	for i := uint16(0); i < 8; i++ {
+		server.registers[i] = 100 + i
+		server.coils[i] = i%2 == 1
+	}
+	type request struct {
+		funcCode          byte
+		address, quantity uint16
+	}
+	var requests []request
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		requests = append(requests, request{pdu[0], binary.BigEndian.Uint16(pdu[1:3]), binary.BigEndian.Uint16(pdu[3:5])})
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	results := client.ExecuteBatch([]BatchOperation{
+		{Operation: "read_holding", SlaveID: 1, Address: 0, Quantity: 2},
+		{Operation: "read_holding", SlaveID: 1, Address: 2, Quantity: 3},
+		{Operation: "read_holding", SlaveID: 1, Address: 5, Quantity: 1},
+		{Operation: "read_holding", SlaveID: 1, Address: 7, Quantity: 1},   // Gap
+		{Operation: "read_holding", SlaveID: 1, Address: 8, Quantity: 125}, // Over the limit
+		{Operation: "read_coils", SlaveID: 1, Address: 0, Quantity: 3},
+		{Operation: "read_coils", SlaveID: 1, Address: 3, Quantity: 5},
+		{Operation: "read_discrete", SlaveID: 1, Address: 8, Quantity: 1}, // Other table
+		{Operation: "read_discrete", SlaveID: 2, Address: 9, Quantity: 1}, // Other slave
+	})
+
+	expectedRegisters := map[int][]uint16{0: {100, 101}, 1: {102, 103, 104}, 2: {105}, 3: {107}}
+	for i, want := range expectedRegisters {
+		got, ok := results[i].Values.([]uint16)
+		if results[i].Error != nil || !ok || len(got) != len(want) || cap(got) != len(want) {
+			t.Fatalf("Operation %d: expected %v, got %v (%v)", i, want, results[i].Values, results[i].Error)
+		}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Errorf("Operation %d: expected %v, got %v", i, want, got)
+				break
+			}
+		}
+	}
+	expectedCoils := map[int][]bool{5: {false, true, false}, 6: {true, false, true, false, true}}
+	for i, want := range expectedCoils {
+		got, ok := results[i].Values.([]bool)
+		if results[i].Error != nil || !ok || len(got) != len(want) || cap(got) != len(want) {
+			t.Fatalf("Operation %d: expected %v, got %v (%v)", i, want, results[i].Values, results[i].Error)
+		}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Errorf("Operation %d: expected %v, got %v", i, want, got)
+				break
+			}
+		}
+	}
+	for i, result := range results {
+		if result.Operation == "" {
+			t.Errorf("Operation %d: expected operation name to be kept", i)
+		}
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	sent := []request{
+		{FuncCodeReadHoldingRegisters, 0, 6},
+		{FuncCodeReadHoldingRegisters, 7, 1},
+		{FuncCodeReadHoldingRegisters, 8, 125},
+		{FuncCodeReadCoils, 0, 8},
+		{FuncCodeReadDiscreteInputs, 8, 1},
+		{FuncCodeReadDiscreteInputs, 9, 1},
+	}
+	if len(requests) != len(sent) {
+		t.Fatalf("Expected %d requests, got %d: %v", len(sent), len(requests), requests)
+	}
+	for i := range sent {
+		if requests[i] != sent[i] {
+			t.Errorf("Request %d: expected %+v, got %+v", i, sent[i], requests[i])
+		}
+	}
+}
+
+// TestBatchCoalescingFallback tests that reads whose merged range the device
+// rejects are retried one at a time
+func TestBatchCoalescingFallback(t *testing.T) {
+	server := NewMockServer()
+	server.registers[0] = 10
+	var requests int
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		requests++
+		// Only address 0 exists, and the device refuses longer reads
+		if binary.BigEndian.Uint16(pdu[1:3]) != 0 || binary.BigEndian.Uint16(pdu[3:5]) != 1 {
+			return []byte{pdu[0] | 0x80, ExceptionIllegalDataAddress}
+		}
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	results := client.ExecuteBatch([]BatchOperation{
+		{Operation: "read_holding", SlaveID: 1, Address: 0, Quantity: 1},
+		{Operation: "read_holding", SlaveID: 1, Address: 1, Quantity: 1},
+	})
+
+	if results[0].Error != nil {
+		t.Errorf("Expected read of address 0 to succeed, got %v", results[0].Error)
+	} else if got := results[0].Values.([]uint16); len(got) != 1 || got[0] != 10 {
+		t.Errorf("Expected [10], got %v", got)
+	}
+	if !errors.Is(results[1].Error, ErrIllegalDataAddress) {
+		t.Errorf("Expected illegal data address for address 1, got %v", results[1].Error)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if requests != 3 {
+		t.Errorf("Expected the merged read and 2 separate reads, got %d requests", requests)
+	}
+}
+
+// TestBatchFloat32 tests float operations in a batch
+func TestBatchFloat32(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	results := client.ExecuteBatch([]BatchOperation{
+		{Operation: "write_float32", SlaveID: 1, Address: 10, Values: []float32{1.5, -2.25}, ByteOrder: ByteOrderLittle},
+		{Operation: "read_float32", SlaveID: 1, Address: 10, Quantity: 2, ByteOrder: ByteOrderLittle},
+		{Operation: "read_holding", SlaveID: 1, Address: 10, Quantity: 2},
+		{Operation: "write_float32", SlaveID: 1, Address: 10, Values: []uint16{1}},
+	})
+
+	for i, result := range results[:3] {
+		if result.Error != nil {
+			t.Fatalf("Operation %d failed: %v", i, result.Error)
+		}
+	}
+	floats, ok := results[1].Values.([]float32)
+	if !ok || len(floats) != 2 || floats[0] != 1.5 || floats[1] != -2.25 {
+		t.Errorf("Expected [1.5 -2.25], got %v", results[1].Values)
+	}
+	// 1.5 is 0x3FC00000; little word order puts the low word first
+	registers := results[2].Values.([]uint16)
+	if registers[0] != 0x0000 || registers[1] != 0x3FC0 {
+		t.Errorf("Expected registers [0x0000 0x3FC0], got %04X", registers)
+	}
+	if results[3].Error == nil {
+		t.Error("Expected error for invalid write_float32 values")
+	}
+}
+
+// TestIdleTimeout tests closing an unused connection
+func TestCloseStopsIdleTimer(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second, IdleTimeout: 20 * time.Millisecond})
+
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	client.mutex.Lock()
+	stopped := client.idleTimer.Stop()
+	client.mutex.Unlock()
+	if stopped {
+		t.Errorf("Expected Close to stop the idle timer")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	client.statsMutex.Lock()
+	idleClosed := client.idleClosed
+	client.statsMutex.Unlock()
+	if idleClosed {
+		t.Errorf("Expected closed client not to be idle closed")
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second, IdleTimeout: 50 * time.Millisecond})
+
+	// Requests keep the connection open past the idle timeout
+	for i := 0; i < 4; i++ {
+		time.Sleep(20 * time.Millisecond)
+		if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+			t.Fatalf("Read on active connection failed: %v", err)
+		}
+	}
+
+	waitFor(t, func() bool { return !client.Connected() })
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); !errors.Is(err, ErrIdleClosed) {
+		t.Errorf("Expected ErrIdleClosed, got %v", err)
+	}
+
+	if err := client.Reconnect(); err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Read after reconnect failed: %v", err)
+	}
+
+	reconnecting := newTestClientWithConfig(t, server, ClientConfig{
+		Timeout:       time.Second,
+		IdleTimeout:   10 * time.Millisecond,
+		AutoReconnect: true,
+	})
+	waitFor(t, func() bool { return !reconnecting.Connected() })
+	if _, err := reconnecting.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Expected AutoReconnect to reopen an idle-closed connection, got %v", err)
+	}
+}
+
+// TestKeepAlivePeriod tests enabling TCP keep-alive on TCP and other connections
+func TestKeepAlivePeriod(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second, KeepAlivePeriod: 30 * time.Second})
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err != nil {
+		t.Errorf("Read with keep-alive enabled failed: %v", err)
+	}
+
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+	if err := enableKeepAlive(local, time.Second); err != nil {
+		t.Errorf("Expected non-TCP connection to be ignored, got %v", err)
+	}
+}
+
+// TestBitField tests extracting and replacing multi-bit fields
+func TestBitField(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	// Bits 0-2 mode = 5, bits 3-4 state = 2, bit 15 set
+	server.registers[7] = 0x8000 | 2<<3 | 5
+
+	tests := []struct {
+		start, width uint8
+		expected     uint16
+	}{
+		{0, 3, 5},
+		{3, 2, 2},
+		{15, 1, 1},
+		{0, 16, 0x8015},
+	}
+	for _, tt := range tests {
+		value, err := client.ReadBitField(1, 7, tt.start, tt.width)
+		if err != nil {
+			t.Fatalf("ReadBitField(%d, %d) failed: %v", tt.start, tt.width, err)
+		}
+		if value != tt.expected {
+			t.Errorf("ReadBitField(%d, %d): expected %d, got %d", tt.start, tt.width, tt.expected, value)
+		}
+	}
+
+	if err := client.WriteBitField(1, 7, 3, 2, 1); err != nil {
+		t.Fatalf("WriteBitField failed: %v", err)
+	}
+	server.mutex.Lock()
+	register := server.registers[7]
+	server.mutex.Unlock()
+	if register != 0x8000|1<<3|5 {
+		t.Errorf("Expected register 0x%04X, got 0x%04X", 0x8000|1<<3|5, register)
+	}
+
+	if _, err := client.ReadBitField(1, 7, 14, 3); err == nil {
+		t.Error("Expected error for field past bit 15")
+	}
+	if _, err := client.ReadBitField(1, 7, 0, 0); err == nil {
+		t.Error("Expected error for zero width field")
+	}
+	if err := client.WriteBitField(1, 7, 0, 3, 8); err == nil {
+		t.Error("Expected error for value wider than the field")
+	}
+}
+
+// TestWriteSingleRegisterVerified tests detecting a clamped setpoint
+func TestWriteSingleRegisterVerified(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second, VerifyDelay: time.Millisecond})
+
+	// The device acknowledges any value but stores at most 100
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] == FuncCodeWriteSingleRegister {
+			value := binary.BigEndian.Uint16(pdu[3:5])
+			if value > 100 {
+				value = 100
+			}
+			server.registers[binary.BigEndian.Uint16(pdu[1:3])] = value
+			return append([]byte(nil), pdu...)
+		}
+		return nil
+	}
+
+	if err := client.WriteSingleRegisterVerified(1, 5, 80); err != nil {
+		t.Errorf("Expected verified write to succeed, got %v", err)
+	}
+
+	err := client.WriteSingleRegisterVerified(1, 5, 150)
+	if err == nil || !strings.Contains(err.Error(), "wrote 150, read 100") {
+		t.Errorf("Expected readback mismatch reporting both values, got %v", err)
+	}
+
+	if err := client.WriteSingleRegisterVerified(BroadcastSlaveID, 5, 80); err == nil {
+		t.Error("Expected verified broadcast write to fail")
+	}
+}
+
+// TestConcurrentClient tests mixed requests from many goroutines on one
+// client; run with -race to check the client's synchronization
+func TestConcurrentClient(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	const goroutines = 16
+	const iterations = 25
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g uint16) {
+			defer wg.Done()
+
+			// Each goroutine owns register g and coil g, so any crossed
+			// response shows up as a wrong value
+			for i := uint16(0); i < iterations; i++ {
+				value := g*1000 + i
+				if err := client.WriteSingleRegister(1, g, value); err != nil {
+					errs <- err
+					return
+				}
+				registers, err := client.ReadHoldingRegisters(1, g, 1)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if registers[0] != value {
+					errs <- fmt.Errorf("register %d: expected %d, got %d", g, value, registers[0])
+					return
+				}
+
+				coil := i%2 == 0
+				if err := client.WriteSingleCoil(1, g, coil); err != nil {
+					errs <- err
+					return
+				}
+				coils, err := client.ReadCoils(1, g, 1)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if coils[0] != coil {
+					errs <- fmt.Errorf("coil %d: expected %v, got %v", g, coil, coils[0])
+					return
+				}
+
+				if err := client.WriteBitField(1, 100, uint8(g%16), 1, uint16(i%2)); err != nil {
+					errs <- err
+					return
+				}
+				client.SetTimeout(time.Second)
+				client.Connected()
+				client.LastSlaveID()
+				client.ErrorRates()
+			}
+		}(uint16(g))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
 // TestErrorHandling tests various error conditions
 func TestErrorHandling(t *testing.T) {
 	tests := []struct {
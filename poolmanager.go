@@ -0,0 +1,144 @@
+package modbus
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultGatewayConcurrency is how many gateways ExecuteBatchMulti works on
+// at once unless SetConcurrency says otherwise
+const DefaultGatewayConcurrency = 8
+
+// PoolManager keeps one connection pool per gateway address, creating each
+// pool the first time its gateway is used
+type PoolManager struct {
+	config PoolConfig // template for new pools; Address is set per gateway
+
+	mutex       sync.Mutex
+	pools       map[string]*ConnectionPool
+	concurrency int
+	closed      bool
+}
+
+// GatewayBatchOperation is a batch operation addressed to a specific gateway
+type GatewayBatchOperation struct {
+	Gateway string // TCP address of the gateway (e.g., "192.168.1.100:502")
+	BatchOperation
+}
+
+// NewPoolManager creates a pool manager. Every pool it opens uses config,
+// with the Address replaced by the gateway's.
+func NewPoolManager(config PoolConfig) *PoolManager {
+	return &PoolManager{
+		config:      config,
+		pools:       make(map[string]*ConnectionPool),
+		concurrency: DefaultGatewayConcurrency,
+	}
+}
+
+// SetConcurrency limits how many gateways ExecuteBatchMulti works on at
+// once. Zero or less restores DefaultGatewayConcurrency.
+func (m *PoolManager) SetConcurrency(n int) {
+	if n <= 0 {
+		n = DefaultGatewayConcurrency
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.concurrency = n
+}
+
+// Pool returns the connection pool for a gateway, creating it if needed
+func (m *PoolManager) Pool(gateway string) (*ConnectionPool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed {
+		return nil, fmt.Errorf("pool manager is closed")
+	}
+	if pool, ok := m.pools[gateway]; ok {
+		return pool, nil
+	}
+
+	config := m.config
+	config.Address = gateway
+	pool, err := NewConnectionPoolWithConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("gateway %s: %w", gateway, err)
+	}
+	m.pools[gateway] = pool
+
+	return pool, nil
+}
+
+// ExecuteBatchMulti runs operations across several gateways. Operations for
+// different gateways run in parallel, up to the manager's concurrency limit,
+// while each gateway's operations run one at a time in the order given, so
+// a write is always seen by the reads after it. Use ReadMany to spread
+// independent reads over a pool's connections. Results are in operation
+// order.
+func (m *PoolManager) ExecuteBatchMulti(operations []GatewayBatchOperation) []BatchResult {
+	results := make([]BatchResult, len(operations))
+
+	m.mutex.Lock()
+	slots := make(chan struct{}, m.concurrency)
+	m.mutex.Unlock()
+
+	// Group operation indices by gateway, keeping their relative order
+	var gateways []string
+	byGateway := make(map[string][]int)
+	for i, op := range operations {
+		if _, ok := byGateway[op.Gateway]; !ok {
+			gateways = append(gateways, op.Gateway)
+		}
+		byGateway[op.Gateway] = append(byGateway[op.Gateway], i)
+	}
+
+	var wg sync.WaitGroup
+	for _, gateway := range gateways {
+		indices := byGateway[gateway]
+
+		pool, err := m.Pool(gateway)
+		if err != nil {
+			for _, i := range indices {
+				results[i] = BatchResult{Operation: operations[i].Operation, Error: err}
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			for _, i := range indices {
+				results[i] = executeOnPool(pool, operations[i].BatchOperation)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// executeOnPool runs one batch operation on a connection borrowed from pool
+func executeOnPool(pool *ConnectionPool, op BatchOperation) BatchResult {
+	client, err := pool.Get()
+	if err != nil {
+		return BatchResult{Operation: op.Operation, Error: err}
+	}
+	defer pool.Put(client)
+
+	return client.executeOperation(op, nil)
+}
+
+// Close closes every pool opened by the manager
+func (m *PoolManager) Close() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.closed = true
+	for gateway, pool := range m.pools {
+		pool.Close()
+		delete(m.pools, gateway)
+	}
+}
@@ -0,0 +1,144 @@
+package modbus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestExecuteBatchMulti tests routing batch operations across two gateways
+func TestExecuteBatchMulti(t *testing.T) {
+	gatewayA := NewMockServer()
+	gatewayA.registers[0] = 100
+	gatewayA.registers[1] = 101
+	addressA := gatewayA.start(t)
+
+	gatewayB := NewMockServer()
+	gatewayB.registers[0] = 200
+	gatewayB.registers[1] = 201
+	addressB := gatewayB.start(t)
+
+	manager := NewPoolManager(PoolConfig{MaxConnections: 2, Timeout: time.Second})
+	defer manager.Close()
+
+	operations := []GatewayBatchOperation{
+		{Gateway: addressA, BatchOperation: BatchOperation{Operation: "read_holding", SlaveID: 1, Address: 0, Quantity: 1}},
+		{Gateway: addressB, BatchOperation: BatchOperation{Operation: "read_holding", SlaveID: 1, Address: 0, Quantity: 1}},
+		{Gateway: addressB, BatchOperation: BatchOperation{Operation: "read_holding", SlaveID: 1, Address: 1, Quantity: 1}},
+		{Gateway: addressA, BatchOperation: BatchOperation{Operation: "write_registers", SlaveID: 1, Address: 5, Values: []uint16{55}}},
+		{Gateway: addressA, BatchOperation: BatchOperation{Operation: "read_holding", SlaveID: 1, Address: 1, Quantity: 1}},
+		{Gateway: "127.0.0.1:1", BatchOperation: BatchOperation{Operation: "read_holding", SlaveID: 1, Address: 0, Quantity: 1}},
+	}
+
+	results := manager.ExecuteBatchMulti(operations)
+	if len(results) != len(operations) {
+		t.Fatalf("Expected %d results, got %d", len(operations), len(results))
+	}
+
+	expected := map[int]uint16{0: 100, 1: 200, 2: 201, 4: 101}
+	for i, value := range expected {
+		if results[i].Error != nil {
+			t.Errorf("Operation %d failed: %v", i, results[i].Error)
+			continue
+		}
+		registers := results[i].Values.([]uint16)
+		if len(registers) != 1 || registers[0] != value {
+			t.Errorf("Operation %d: expected [%d], got %v", i, value, registers)
+		}
+	}
+
+	if results[3].Error != nil {
+		t.Errorf("Write operation failed: %v", results[3].Error)
+	}
+	gatewayA.mutex.Lock()
+	writtenA := gatewayA.registers[5]
+	gatewayA.mutex.Unlock()
+	gatewayB.mutex.Lock()
+	_, writtenB := gatewayB.registers[5]
+	gatewayB.mutex.Unlock()
+	if writtenA != 55 || writtenB {
+		t.Errorf("Expected write routed to gateway A only, got A=%d, B written=%v", writtenA, writtenB)
+	}
+
+	if results[5].Error == nil {
+		t.Error("Expected error for unreachable gateway")
+	}
+	if results[5].Operation != "read_holding" {
+		t.Errorf("Expected operation name on failed result, got %q", results[5].Operation)
+	}
+}
+
+// TestExecuteBatchMultiOrder tests that a gateway's operations run in order
+// even when its pool has several connections
+func TestExecuteBatchMultiOrder(t *testing.T) {
+	gateway := NewMockServer()
+	gateway.handler = func(unitID byte, pdu []byte) []byte {
+		if pdu[0] == FuncCodeWriteMultipleRegisters {
+			time.Sleep(20 * time.Millisecond) // A slow write the reads must wait for
+		}
+		return nil
+	}
+	address := gateway.start(t)
+
+	manager := NewPoolManager(PoolConfig{MaxConnections: 4, Timeout: time.Second})
+	defer manager.Close()
+
+	var operations []GatewayBatchOperation
+	for value := uint16(1); value <= 3; value++ {
+		operations = append(operations,
+			GatewayBatchOperation{Gateway: address, BatchOperation: BatchOperation{Operation: "write_registers", SlaveID: 1, Address: 9, Values: []uint16{value}}},
+			GatewayBatchOperation{Gateway: address, BatchOperation: BatchOperation{Operation: "read_holding", SlaveID: 1, Address: 9, Quantity: 1}},
+		)
+	}
+
+	results := manager.ExecuteBatchMulti(operations)
+	for i := 1; i < len(results); i += 2 {
+		if results[i].Error != nil {
+			t.Fatalf("Operation %d failed: %v", i, results[i].Error)
+		}
+		if got := results[i].Values.([]uint16)[0]; got != uint16(i/2+1) {
+			t.Errorf("Read %d: expected the preceding write's %d, got %d", i, i/2+1, got)
+		}
+	}
+}
+
+// TestExecuteBatchMultiConcurrency tests that no more gateways than the
+// limit are worked on at once
+func TestExecuteBatchMultiConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	handler := func(unitID byte, pdu []byte) []byte {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return nil
+	}
+
+	manager := NewPoolManager(PoolConfig{MaxConnections: 1, Timeout: time.Second})
+	defer manager.Close()
+	manager.SetConcurrency(2)
+
+	var operations []GatewayBatchOperation
+	for i := 0; i < 5; i++ {
+		gateway := NewMockServer()
+		gateway.handler = handler
+		operations = append(operations, GatewayBatchOperation{
+			Gateway:        gateway.start(t),
+			BatchOperation: BatchOperation{Operation: "read_holding", SlaveID: 1, Address: 0, Quantity: 1},
+		})
+	}
+
+	for i, result := range manager.ExecuteBatchMulti(operations) {
+		if result.Error != nil {
+			t.Errorf("Operation %d failed: %v", i, result.Error)
+		}
+	}
+	if got := atomic.LoadInt32(&peak); got != 2 {
+		t.Errorf("Expected at most 2 gateways at once (and the limit reached), got %d", got)
+	}
+}
@@ -0,0 +1,67 @@
+package modbus
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ReadRequest is one read in a ReadMany call
+type ReadRequest struct {
+	Operation string    // "read_coils", "read_discrete", "read_holding", "read_input" or "read_float32"
+	SlaveID   byte      // Slave ID
+	Address   uint16    // Starting address
+	Quantity  uint16    // Quantity to read (number of floats for read_float32)
+	ByteOrder ByteOrder // Word order for read_float32 (default DefaultByteOrder)
+}
+
+// ReadMany runs independent reads in parallel over connections borrowed
+// from pool, with at most concurrency of them in flight. Concurrency is
+// capped at the pool size, and zero or less means the pool size. Results
+// are in request order and carry the same values as ExecuteBatch.
+func ReadMany(pool *ConnectionPool, requests []ReadRequest, concurrency int) []BatchResult {
+	results := make([]BatchResult, len(requests))
+
+	if concurrency <= 0 || concurrency > pool.maxConn {
+		concurrency = pool.maxConn
+	}
+	if concurrency > len(requests) {
+		concurrency = len(requests)
+	}
+
+	queue := make(chan int, len(requests))
+	for i := range requests {
+		queue <- i
+	}
+	close(queue)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = readOnPool(pool, requests[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// readOnPool runs one read request on a connection borrowed from pool
+func readOnPool(pool *ConnectionPool, req ReadRequest) BatchResult {
+	switch req.Operation {
+	case "read_coils", "read_discrete", "read_holding", "read_input", "read_float32":
+	default:
+		return BatchResult{Operation: req.Operation, Error: fmt.Errorf("not a read operation: %s", req.Operation)}
+	}
+
+	return executeOnPool(pool, BatchOperation{
+		Operation: req.Operation,
+		SlaveID:   req.SlaveID,
+		Address:   req.Address,
+		Quantity:  req.Quantity,
+		ByteOrder: req.ByteOrder,
+	})
+}
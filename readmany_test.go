@@ -0,0 +1,58 @@
+package modbus
+
+import (
+	"testing"
+	"time"
+)
+
+// TestReadMany tests fanning reads out over a pool with results in order
+func TestReadMany(t *testing.T) {
+	server := NewMockServer()
+	for i := uint16(0); i < 50; i++ {
+		server.registers[i] = 1000 + i
+	}
+	server.coils[7] = true
+
+	pool, err := NewConnectionPool(server.start(t), 4, time.Second)
+	if err != nil {
+		t.Fatalf("NewConnectionPool failed: %v", err)
+	}
+	defer pool.Close()
+
+	var requests []ReadRequest
+	for i := uint16(0); i < 50; i++ {
+		requests = append(requests, ReadRequest{Operation: "read_holding", SlaveID: byte(1 + i%5), Address: i, Quantity: 1})
+	}
+	requests = append(requests,
+		ReadRequest{Operation: "read_coils", SlaveID: 1, Address: 7, Quantity: 1},
+		ReadRequest{Operation: "write_registers", SlaveID: 1, Address: 0, Quantity: 1},
+	)
+
+	for _, concurrency := range []int{0, 1, 3, 100} {
+		results := ReadMany(pool, requests, concurrency)
+		if len(results) != len(requests) {
+			t.Fatalf("Expected %d results, got %d", len(requests), len(results))
+		}
+
+		for i := 0; i < 50; i++ {
+			if results[i].Error != nil {
+				t.Errorf("Concurrency %d: request %d failed: %v", concurrency, i, results[i].Error)
+				continue
+			}
+			registers := results[i].Values.([]uint16)
+			if registers[0] != uint16(1000+i) {
+				t.Errorf("Concurrency %d: request %d: expected %d, got %d", concurrency, i, 1000+i, registers[0])
+			}
+		}
+		if coils, ok := results[50].Values.([]bool); !ok || !coils[0] {
+			t.Errorf("Concurrency %d: expected coil read [true], got %v (err %v)", concurrency, results[50].Values, results[50].Error)
+		}
+		if results[51].Error == nil {
+			t.Errorf("Concurrency %d: expected error for a write operation", concurrency)
+		}
+	}
+
+	if results := ReadMany(pool, nil, 4); len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
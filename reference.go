@@ -0,0 +1,131 @@
+package modbus
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Table identifies one of the four Modbus data tables
+type Table byte
+
+// Data tables, numbered by the leading digit of their conventional references
+const (
+	TableCoils            Table = 0 // 0xxxx, read/write bits
+	TableDiscreteInputs   Table = 1 // 1xxxx, read-only bits
+	TableInputRegisters   Table = 3 // 3xxxx, read-only registers
+	TableHoldingRegisters Table = 4 // 4xxxx, read/write registers
+)
+
+// String returns the table's name
+func (t Table) String() string {
+	switch t {
+	case TableCoils:
+		return "coils"
+	case TableDiscreteInputs:
+		return "discrete inputs"
+	case TableInputRegisters:
+		return "input registers"
+	case TableHoldingRegisters:
+		return "holding registers"
+	default:
+		return fmt.Sprintf("unknown table %d", byte(t))
+	}
+}
+
+// Reference is a location in a device's data tables
+type Reference struct {
+	Table   Table
+	Address uint16 // 0-based address as sent on the wire
+}
+
+// ParseReference parses a conventional 1-based data reference such as
+// "40001" (the first holding register) into its table and 0-based wire
+// address. The leading digit selects the table; it is followed by four
+// digits (1-9999) or, in the extended six-digit form such as "400001", five
+// digits (1-65536).
+func ParseReference(ref string) (Reference, error) {
+	if len(ref) != 5 && len(ref) != 6 {
+		return Reference{}, fmt.Errorf("invalid reference %q: must be 5 or 6 digits", ref)
+	}
+	for _, r := range ref {
+		if r < '0' || r > '9' {
+			return Reference{}, fmt.Errorf("invalid reference %q: must be 5 or 6 digits", ref)
+		}
+	}
+
+	table := Table(ref[0] - '0')
+	switch table {
+	case TableCoils, TableDiscreteInputs, TableInputRegisters, TableHoldingRegisters:
+	default:
+		return Reference{}, fmt.Errorf("invalid reference %q: unknown table %c", ref, ref[0])
+	}
+
+	offset, _ := strconv.Atoi(ref[1:])
+	if offset < 1 || offset > 0x10000 {
+		return Reference{}, fmt.Errorf("invalid reference %q: offset must be 1-65536", ref)
+	}
+
+	return Reference{Table: table, Address: uint16(offset - 1)}, nil
+}
+
+// ReadRegistersRef reads quantity registers starting at a 3xxxx (input) or
+// 4xxxx (holding) reference
+func (c *Client) ReadRegistersRef(slaveID byte, ref string, quantity uint16) ([]uint16, error) {
+	reference, err := ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	switch reference.Table {
+	case TableInputRegisters:
+		return c.ReadInputRegisters(slaveID, reference.Address, quantity)
+	case TableHoldingRegisters:
+		return c.ReadHoldingRegisters(slaveID, reference.Address, quantity)
+	default:
+		return nil, fmt.Errorf("reference %s is in %s, not a register table", ref, reference.Table)
+	}
+}
+
+// ReadBitsRef reads quantity bits starting at a 0xxxx (coil) or 1xxxx
+// (discrete input) reference
+func (c *Client) ReadBitsRef(slaveID byte, ref string, quantity uint16) ([]bool, error) {
+	reference, err := ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	switch reference.Table {
+	case TableCoils:
+		return c.ReadCoils(slaveID, reference.Address, quantity)
+	case TableDiscreteInputs:
+		return c.ReadDiscreteInputs(slaveID, reference.Address, quantity)
+	default:
+		return nil, fmt.Errorf("reference %s is in %s, not a bit table", ref, reference.Table)
+	}
+}
+
+// WriteRegistersRef writes holding registers starting at a 4xxxx reference
+func (c *Client) WriteRegistersRef(slaveID byte, ref string, values []uint16) error {
+	reference, err := ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	if reference.Table != TableHoldingRegisters {
+		return fmt.Errorf("reference %s is in %s, which is not writable registers", ref, reference.Table)
+	}
+
+	return c.WriteMultipleRegisters(slaveID, reference.Address, values)
+}
+
+// WriteCoilsRef writes coils starting at a 0xxxx reference
+func (c *Client) WriteCoilsRef(slaveID byte, ref string, values []bool) error {
+	reference, err := ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	if reference.Table != TableCoils {
+		return fmt.Errorf("reference %s is in %s, which is not writable bits", ref, reference.Table)
+	}
+
+	return c.WriteMultipleCoils(slaveID, reference.Address, values)
+}
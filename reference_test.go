@@ -0,0 +1,89 @@
+package modbus
+
+import "testing"
+
+// TestParseReference tests translating conventional references
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected Reference
+		valid    bool
+	}{
+		{"00001", Reference{TableCoils, 0}, true},
+		{"10010", Reference{TableDiscreteInputs, 9}, true},
+		{"30001", Reference{TableInputRegisters, 0}, true},
+		{"40001", Reference{TableHoldingRegisters, 0}, true},
+		{"49999", Reference{TableHoldingRegisters, 9998}, true},
+		{"400001", Reference{TableHoldingRegisters, 0}, true},
+		{"465536", Reference{TableHoldingRegisters, 65535}, true},
+		{"40000", Reference{}, false},  // Offsets are 1-based
+		{"465537", Reference{}, false}, // Past the address space
+		{"20001", Reference{}, false},  // No such table
+		{"4001", Reference{}, false},
+		{"4000a", Reference{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			reference, err := ParseReference(tt.ref)
+			if !tt.valid {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", reference)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseReference failed: %v", err)
+			}
+			if reference != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, reference)
+			}
+		})
+	}
+}
+
+// TestReferenceAccess tests that references select the right function code
+func TestReferenceAccess(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		switch pdu[0] {
+		case FuncCodeReadInputRegisters:
+			return []byte{pdu[0], 2, 0x00, 0x03}
+		case FuncCodeReadDiscreteInputs:
+			return []byte{pdu[0], 1, 0x01}
+		}
+		return nil
+	}
+	client := newTestClient(t, server)
+
+	if err := client.WriteRegistersRef(1, "40005", []uint16{44}); err != nil {
+		t.Fatalf("WriteRegistersRef failed: %v", err)
+	}
+	if err := client.WriteCoilsRef(1, "00003", []bool{true}); err != nil {
+		t.Fatalf("WriteCoilsRef failed: %v", err)
+	}
+
+	holding, err := client.ReadRegistersRef(1, "40005", 1)
+	if err != nil || holding[0] != 44 {
+		t.Errorf("Expected holding register 40005 = 44, got %v (err %v)", holding, err)
+	}
+	input, err := client.ReadRegistersRef(1, "30005", 1)
+	if err != nil || input[0] != 3 {
+		t.Errorf("Expected input register read, got %v (err %v)", input, err)
+	}
+	coils, err := client.ReadBitsRef(1, "00003", 1)
+	if err != nil || !coils[0] {
+		t.Errorf("Expected coil 00003 on, got %v (err %v)", coils, err)
+	}
+	inputs, err := client.ReadBitsRef(1, "10001", 1)
+	if err != nil || !inputs[0] {
+		t.Errorf("Expected discrete input read, got %v (err %v)", inputs, err)
+	}
+
+	if _, err := client.ReadRegistersRef(1, "00001", 1); err == nil {
+		t.Error("Expected error reading registers from the coil table")
+	}
+	if err := client.WriteRegistersRef(1, "30001", []uint16{1}); err == nil {
+		t.Error("Expected error writing input registers")
+	}
+}
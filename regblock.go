@@ -0,0 +1,74 @@
+package modbus
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// RegisterBlock is a block of holding registers read once and viewable as
+// several types. Every view decodes the same registers, so switching between
+// them costs no further requests.
+type RegisterBlock struct {
+	Address   uint16 // Address of the first register
+	registers []uint16
+}
+
+// ReadBlock reads quantity holding registers starting at address, splitting
+// the read into as many requests as the register limit requires
+func (c *Client) ReadBlock(slaveID byte, address, quantity uint16) (*RegisterBlock, error) {
+	registers, err := c.ReadRegistersChunked(slaveID, address, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return &RegisterBlock{Address: address, registers: registers}, nil
+}
+
+// Len returns the number of registers in the block
+func (b *RegisterBlock) Len() int {
+	return len(b.registers)
+}
+
+// Uint16s returns a copy of the raw register values
+func (b *RegisterBlock) Uint16s() []uint16 {
+	return append([]uint16(nil), b.registers...)
+}
+
+// Int16s returns the registers as signed (two's complement) values
+func (b *RegisterBlock) Int16s() []int16 {
+	values := make([]int16, len(b.registers))
+	for i, reg := range b.registers {
+		values[i] = int16(reg)
+	}
+	return values
+}
+
+// Bytes returns the registers exactly as sent on the wire, two bytes per
+// register, high byte first
+func (b *RegisterBlock) Bytes() []byte {
+	return registersToBytes(b.registers, false)
+}
+
+// Float32s returns consecutive register pairs as 32-bit floats in the given
+// word order. The block must hold an even number of registers.
+func (b *RegisterBlock) Float32s(byteOrder ByteOrder) ([]float32, error) {
+	if len(b.registers)%2 != 0 {
+		return nil, fmt.Errorf("odd register count %d: float32 values take two registers each", len(b.registers))
+	}
+
+	values := make([]float32, len(b.registers)/2)
+	for i := range values {
+		bits, err := registersToUint32(b.registers[2*i:2*i+2], byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = math.Float32frombits(bits)
+	}
+	return values, nil
+}
+
+// ASCII returns the registers as text packed two characters per register,
+// high byte first, with trailing NULs trimmed as ReadString does
+func (b *RegisterBlock) ASCII() string {
+	return strings.TrimRight(string(b.Bytes()), "\x00")
+}
@@ -0,0 +1,66 @@
+package modbus
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// TestReadBlock tests typed views over a single register read
+func TestReadBlock(t *testing.T) {
+	transport := NewMemoryTransport()
+	bits := math.Float32bits(1.5)
+	values := []uint16{uint16(bits >> 16), uint16(bits), 0xFFFE, 0x4142}
+	for i, v := range values {
+		transport.SetRegister(10+uint16(i), v)
+	}
+	requests := 0
+	client := NewClientWithTransport(transport, ClientConfig{
+		OnTransaction: func(request, response []byte, err error) { requests++ },
+	})
+
+	block, err := client.ReadBlock(1, 10, 4)
+	if err != nil {
+		t.Fatalf("ReadBlock failed: %v", err)
+	}
+
+	if block.Address != 10 || block.Len() != 4 {
+		t.Errorf("Expected 4 registers at 10, got %d at %d", block.Len(), block.Address)
+	}
+	if raw := block.Uint16s(); !reflect.DeepEqual(raw, values) {
+		t.Errorf("Expected %v, got %v", values, raw)
+	}
+	if signed := block.Int16s(); signed[2] != -2 || signed[3] != 0x4142 {
+		t.Errorf("Expected [.. -2 16706], got %v", signed)
+	}
+	if data := block.Bytes(); len(data) != 8 || data[6] != 'A' || data[7] != 'B' {
+		t.Errorf("Expected wire bytes ending in AB, got % X", data)
+	}
+	floats, err := block.Float32s(ByteOrderBig)
+	if err != nil || floats[0] != 1.5 {
+		t.Errorf("Expected first float 1.5, got %v (err %v)", floats, err)
+	}
+	if _, err := block.Float32s(ByteOrder("bogus")); err == nil {
+		t.Error("Expected error for an invalid byte order")
+	}
+	if requests != 1 {
+		t.Errorf("Expected views to reuse one request, got %d", requests)
+	}
+
+	// Views don't share storage with the block
+	block.Uint16s()[0] = 0
+	if block.Uint16s()[0] != values[0] {
+		t.Error("Expected Uint16s to return a copy")
+	}
+
+	text, err := client.ReadBlock(1, 13, 1)
+	if err != nil {
+		t.Fatalf("ReadBlock failed: %v", err)
+	}
+	if s := text.ASCII(); s != "AB" {
+		t.Errorf("Expected \"AB\", got %q", s)
+	}
+	if _, err := text.Float32s(ByteOrderBig); err == nil {
+		t.Error("Expected error for an odd register count")
+	}
+}
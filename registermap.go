@@ -0,0 +1,215 @@
+package modbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// MapEntry is one named point in a RegisterMap
+type MapEntry struct {
+	Name string `json:"name"`
+
+	// Ref is the point's conventional reference, e.g. "40001" for the first
+	// holding register; see ParseReference
+	Ref string `json:"ref"`
+
+	// Type is "bool" for coils and discrete inputs, or one of "uint16",
+	// "int16", "uint32", "int32" and "float32" for registers
+	Type string `json:"type"`
+
+	// ByteOrder is the word order of 32-bit types (default DefaultByteOrder)
+	ByteOrder ByteOrder `json:"byte_order,omitempty"`
+
+	// Scale, if nonzero, multiplies a numeric value, which is then returned
+	// as float64
+	Scale float64 `json:"scale,omitempty"`
+}
+
+// RegisterMap is a device's point list: named coils, inputs and registers
+// with their types, as kept in a descriptor file
+type RegisterMap struct {
+	entries    []MapEntry
+	references []Reference // Parsed Ref of each entry
+}
+
+// registerWidths is the number of registers each register type occupies
+var registerWidths = map[string]uint16{
+	"uint16":  1,
+	"int16":   1,
+	"uint32":  2,
+	"int32":   2,
+	"float32": 2,
+}
+
+// NewRegisterMap validates entries and builds a register map from them
+func NewRegisterMap(entries []MapEntry) (*RegisterMap, error) {
+	m := &RegisterMap{
+		entries:    append([]MapEntry(nil), entries...),
+		references: make([]Reference, len(entries)),
+	}
+
+	names := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("entry %d: missing name", i)
+		}
+		if names[entry.Name] {
+			return nil, fmt.Errorf("entry %s: duplicate name", entry.Name)
+		}
+		names[entry.Name] = true
+
+		reference, err := ParseReference(entry.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("entry %s: %w", entry.Name, err)
+		}
+		m.references[i] = reference
+
+		bitTable := reference.Table == TableCoils || reference.Table == TableDiscreteInputs
+		if bitTable {
+			if entry.Type != "bool" {
+				return nil, fmt.Errorf("entry %s: %s hold bool values, not %q", entry.Name, reference.Table, entry.Type)
+			}
+			continue
+		}
+		width, ok := registerWidths[entry.Type]
+		if !ok {
+			return nil, fmt.Errorf("entry %s: invalid register type %q", entry.Name, entry.Type)
+		}
+		if err := checkAddressRange(reference.Address, int(width)); err != nil {
+			return nil, fmt.Errorf("entry %s: %w", entry.Name, err)
+		}
+	}
+
+	return m, nil
+}
+
+// LoadRegisterMap reads a JSON descriptor holding an array of entries
+func LoadRegisterMap(r io.Reader) (*RegisterMap, error) {
+	var entries []MapEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("invalid register map: %w", err)
+	}
+	return NewRegisterMap(entries)
+}
+
+// LoadRegisterMapFile reads a JSON descriptor file; see LoadRegisterMap
+func LoadRegisterMapFile(path string) (*RegisterMap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadRegisterMap(file)
+}
+
+// ReadAll reads every point from the device and returns the decoded values
+// by name. Points in the same table that overlap or touch are read
+// together, so a dense map costs one request per block rather than per
+// point. Values are bool, uint16, int16, uint32, int32 or float32 as the
+// entry's type says, or float64 for scaled entries.
+func (m *RegisterMap) ReadAll(client *Client, slaveID byte) (map[string]interface{}, error) {
+	// Plan one set of spans per table
+	ranges := make(map[Table][]Range)
+	for i, entry := range m.entries {
+		reference := m.references[i]
+		width := uint16(1)
+		if entry.Type != "bool" {
+			width = registerWidths[entry.Type]
+		}
+		ranges[reference.Table] = append(ranges[reference.Table], Range{Address: reference.Address, Quantity: width})
+	}
+
+	registers := make(map[Table]map[uint16]uint16)
+	bits := make(map[Table]map[uint16]bool)
+	for table, tableRanges := range ranges {
+		for _, span := range planBlocks(tableRanges, 0) {
+			switch table {
+			case TableCoils, TableDiscreteInputs:
+				funcCode := byte(FuncCodeReadCoils)
+				if table == TableDiscreteInputs {
+					funcCode = FuncCodeReadDiscreteInputs
+				}
+				values, err := client.readBitsChunked(slaveID, funcCode, span.Address, span.Quantity)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", table, err)
+				}
+				if bits[table] == nil {
+					bits[table] = make(map[uint16]bool)
+				}
+				for i, value := range values {
+					bits[table][span.Address+uint16(i)] = value
+				}
+
+			default:
+				funcCode := byte(FuncCodeReadHoldingRegisters)
+				if table == TableInputRegisters {
+					funcCode = FuncCodeReadInputRegisters
+				}
+				values, err := client.readRegistersChunked(slaveID, funcCode, span.Address, span.Quantity, 1)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", table, err)
+				}
+				if registers[table] == nil {
+					registers[table] = make(map[uint16]uint16)
+				}
+				for i, value := range values {
+					registers[table][span.Address+uint16(i)] = value
+				}
+			}
+		}
+	}
+
+	results := make(map[string]interface{}, len(m.entries))
+	for i, entry := range m.entries {
+		reference := m.references[i]
+		if entry.Type == "bool" {
+			results[entry.Name] = bits[reference.Table][reference.Address]
+			continue
+		}
+
+		table := registers[reference.Table]
+		value, err := decodeMapValue(entry, table[reference.Address], table[reference.Address+1])
+		if err != nil {
+			return nil, fmt.Errorf("entry %s: %w", entry.Name, err)
+		}
+		results[entry.Name] = value
+	}
+
+	return results, nil
+}
+
+// decodeMapValue decodes a register entry from its first register and, for
+// 32-bit types, the one after it
+func decodeMapValue(entry MapEntry, first, second uint16) (interface{}, error) {
+	var value interface{}
+	var number float64
+
+	switch entry.Type {
+	case "uint16":
+		value, number = first, float64(first)
+	case "int16":
+		value, number = int16(first), float64(int16(first))
+	default:
+		bits, err := registersToUint32([]uint16{first, second}, entry.ByteOrder)
+		if err != nil {
+			return nil, err
+		}
+		switch entry.Type {
+		case "uint32":
+			value, number = bits, float64(bits)
+		case "int32":
+			value, number = int32(bits), float64(int32(bits))
+		default:
+			f := math.Float32frombits(bits)
+			value, number = f, float64(f)
+		}
+	}
+
+	if entry.Scale != 0 {
+		return number * entry.Scale, nil
+	}
+	return value, nil
+}
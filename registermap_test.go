@@ -0,0 +1,123 @@
+package modbus
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDescriptor = `[
+	{"name": "running",     "ref": "00001", "type": "bool"},
+	{"name": "fault",       "ref": "00002", "type": "bool"},
+	{"name": "door_open",   "ref": "10003", "type": "bool"},
+	{"name": "setpoint",    "ref": "40001", "type": "uint16"},
+	{"name": "offset",      "ref": "40002", "type": "int16"},
+	{"name": "flow",        "ref": "40003", "type": "float32", "byte_order": "big"},
+	{"name": "temperature", "ref": "30011", "type": "int16", "scale": 0.1},
+	{"name": "hours",       "ref": "30012", "type": "uint32", "byte_order": "big"}
+]`
+
+// TestRegisterMapReadAll tests reading a point list with coalesced requests
+func TestRegisterMapReadAll(t *testing.T) {
+	registerMap, err := LoadRegisterMap(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatalf("LoadRegisterMap failed: %v", err)
+	}
+
+	device := NewMemoryTransport()
+	device.SetCoil(0, true)
+	device.SetCoil(2, true)
+	device.SetRegister(0, 500)
+	device.SetRegister(1, 0xFFFB) // -5
+	flow := math.Float32bits(12.5)
+	device.SetRegister(2, uint16(flow>>16))
+	device.SetRegister(3, uint16(flow))
+	device.SetRegister(10, 0xFF38) // -200
+	device.SetRegister(11, 0x0001)
+	device.SetRegister(12, 0x0002)
+
+	requests := 0
+	client := NewClientWithTransport(device, ClientConfig{
+		OnTransaction: func(request, response []byte, err error) { requests++ },
+	})
+
+	values, err := registerMap.ReadAll(client, 1)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"running":     true,
+		"fault":       false,
+		"door_open":   true,
+		"setpoint":    uint16(500),
+		"offset":      int16(-5),
+		"flow":        float32(12.5),
+		"temperature": -20.0,
+		"hours":       uint32(0x00010002),
+	}
+	for name, want := range expected {
+		got := values[name]
+		if f, ok := want.(float64); ok {
+			if g, ok := got.(float64); !ok || math.Abs(g-f) > 1e-9 {
+				t.Errorf("%s: expected %v, got %#v", name, want, got)
+			}
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: expected %#v, got %#v", name, want, got)
+		}
+	}
+	if len(values) != len(expected) {
+		t.Errorf("Expected %d values, got %d", len(expected), len(values))
+	}
+
+	// One request each for coils, discrete inputs, holding and input registers
+	if requests != 4 {
+		t.Errorf("Expected 4 coalesced requests, got %d", requests)
+	}
+}
+
+// TestLoadRegisterMapFile tests loading a descriptor from disk
+func TestLoadRegisterMapFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "device.json")
+	if err := os.WriteFile(path, []byte(testDescriptor), 0o644); err != nil {
+		t.Fatalf("Failed to write descriptor: %v", err)
+	}
+	if _, err := LoadRegisterMapFile(path); err != nil {
+		t.Errorf("LoadRegisterMapFile failed: %v", err)
+	}
+	if _, err := LoadRegisterMapFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
+
+// TestRegisterMapValidation tests rejection of malformed descriptors
+func TestRegisterMapValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []MapEntry
+	}{
+		{"missing name", []MapEntry{{Ref: "40001", Type: "uint16"}}},
+		{"duplicate name", []MapEntry{{Name: "a", Ref: "40001", Type: "uint16"}, {Name: "a", Ref: "40002", Type: "uint16"}}},
+		{"bad reference", []MapEntry{{Name: "a", Ref: "20001", Type: "uint16"}}},
+		{"bool register", []MapEntry{{Name: "a", Ref: "40001", Type: "bool"}}},
+		{"numeric coil", []MapEntry{{Name: "a", Ref: "00001", Type: "uint16"}}},
+		{"unknown type", []MapEntry{{Name: "a", Ref: "40001", Type: "float64"}}},
+		{"past the end", []MapEntry{{Name: "a", Ref: "465536", Type: "float32"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRegisterMap(tt.entries); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	if _, err := LoadRegisterMap(strings.NewReader(`{"name": "not an array"}`)); err == nil {
+		t.Error("Expected error for a descriptor that is not an array")
+	}
+}
@@ -0,0 +1,129 @@
+package modbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy controls how a ReliableClient rides out transport failures
+type RetryPolicy struct {
+	MaxAttempts int           // Tries per operation, the first included (default 5)
+	Backoff     time.Duration // Wait before the first retry, doubled after each (default 100ms)
+	MaxBackoff  time.Duration // Upper bound on the wait between retries (default 5s)
+}
+
+// withDefaults fills in unset policy fields
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 5
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 5 * time.Second
+	}
+	return p
+}
+
+// backoff returns the wait after the given number of failed attempts
+func (p RetryPolicy) backoff(failed int) time.Duration {
+	wait := p.Backoff
+	for i := 1; i < failed && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+// ReliableClient is a Client that keeps working through device reboots and
+// dropped connections. Every operation that fails with a transport error is
+// retried on a fresh connection, with exponential backoff, until it succeeds
+// or the policy's attempts run out. Exception responses are returned at once,
+// as the device did answer.
+//
+// All Client methods are available on a ReliableClient, and the embedded
+// Client can be passed wherever a *Client is expected; it keeps retrying.
+// Because failed requests are re-sent, a write may reach the device twice.
+type ReliableClient struct {
+	*Client
+}
+
+// NewReliableClient connects to the device, retrying the initial connection
+// under policy as well, and returns a client that retries every operation
+func NewReliableClient(config ClientConfig, policy RetryPolicy) (*ReliableClient, error) {
+	policy = policy.withDefaults()
+
+	for attempt := 1; ; attempt++ {
+		client, err := NewClient(config)
+		if err == nil {
+			client.retryPolicy = &policy
+			return &ReliableClient{Client: client}, nil
+		}
+		if attempt >= policy.MaxAttempts {
+			return nil, fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		}
+		time.Sleep(policy.backoff(attempt))
+	}
+}
+
+// sleepContext waits for d, returning false early if ctx, which may be nil,
+// ends first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if ctx == nil {
+		time.Sleep(d)
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// attemptWithPolicy performs a round trip, reconnecting and retrying under
+// the client's retry policy while it fails with transport errors
+func (c *Client) attemptWithPolicy(slaveID byte, pdu []byte, opts requestOptions) ([]byte, error) {
+	policy := c.retryPolicy
+
+	response, err := c.roundTrip(slaveID, pdu, opts)
+	if c.reconnectHookRunning() {
+		return response, err
+	}
+	for failed := 1; isTransportError(err); failed++ {
+		if failed >= policy.MaxAttempts {
+			return nil, fmt.Errorf("gave up after %d attempts: %w", failed, err)
+		}
+		wait := policy.backoff(failed)
+		if !opts.deadline.IsZero() && time.Now().Add(wait).After(opts.deadline) {
+			return nil, err
+		}
+
+		c.statsMutex.Lock()
+		closed := c.closed
+		c.statsMutex.Unlock()
+		if closed {
+			return nil, err
+		}
+
+		if !sleepContext(opts.ctx, wait) {
+			return nil, err
+		}
+		if reconnectErr := c.Reconnect(); reconnectErr != nil {
+			if errors.As(reconnectErr, new(*hookError)) {
+				return nil, reconnectErr
+			}
+			err = reconnectErr
+			continue
+		}
+		response, err = c.roundTrip(slaveID, pdu, opts)
+	}
+	return response, err
+}
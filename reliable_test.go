@@ -0,0 +1,128 @@
+package modbus
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRetryPolicyBackoff tests exponential backoff capped at MaxBackoff
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}.withDefaults()
+
+	tests := []struct {
+		failed   int
+		expected time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{50, time.Second},
+	}
+
+	for _, tt := range tests {
+		if wait := policy.backoff(tt.failed); wait != tt.expected {
+			t.Errorf("After %d failures: expected %v, got %v", tt.failed, tt.expected, wait)
+		}
+	}
+}
+
+// TestReliableClientReboot tests riding out a device that goes away and
+// comes back on the same address
+func TestReliableClientReboot(t *testing.T) {
+	server := NewMockServer()
+	server.registers[0] = 42
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := server.serve(t, listener)
+
+	client, err := NewReliableClient(ClientConfig{Address: address, Timeout: time.Second},
+		RetryPolicy{MaxAttempts: 20, Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewReliableClient failed: %v", err)
+	}
+	defer client.Close()
+
+	// Take the device down, then bring it back a little later
+	listener.Close()
+	server.dropConnections()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		relistener, err := net.Listen("tcp", address)
+		if err != nil {
+			t.Errorf("Failed to listen again: %v", err)
+			return
+		}
+		server.serve(t, relistener)
+	}()
+
+	registers, err := client.ReadHoldingRegisters(1, 0, 1)
+	if err != nil {
+		t.Fatalf("Expected read to succeed once the device is back, got %v", err)
+	}
+	if registers[0] != 42 {
+		t.Errorf("Expected 42, got %d", registers[0])
+	}
+}
+
+// TestReliableClientGivesUp tests the error once the policy is exhausted
+func TestReliableClientGivesUp(t *testing.T) {
+	server := NewMockServer()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := server.serve(t, listener)
+
+	client, err := NewReliableClient(ClientConfig{Address: address, Timeout: time.Second},
+		RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewReliableClient failed: %v", err)
+	}
+	defer client.Close()
+
+	listener.Close()
+	server.dropConnections()
+
+	_, err = client.ReadHoldingRegisters(1, 0, 1)
+	if err == nil || !strings.Contains(err.Error(), "gave up after 3 attempts") {
+		t.Errorf("Expected to give up after 3 attempts, got %v", err)
+	}
+
+	if _, err := NewReliableClient(ClientConfig{Address: address, Timeout: time.Second},
+		RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}); err == nil {
+		t.Error("Expected error connecting to a device that is down")
+	}
+}
+
+// TestReliableClientException tests that exception responses are not retried
+func TestReliableClientException(t *testing.T) {
+	server := NewMockServer()
+	requests := 0
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		requests++
+		return []byte{pdu[0] | 0x80, ExceptionIllegalDataAddress}
+	}
+
+	client, err := NewReliableClient(ClientConfig{Address: server.start(t), Timeout: time.Second},
+		RetryPolicy{Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewReliableClient failed: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.ReadHoldingRegisters(1, 0, 1); err == nil {
+		t.Fatal("Expected exception error")
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
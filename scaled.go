@@ -0,0 +1,45 @@
+package modbus
+
+import "fmt"
+
+// Scale maps a raw register count to engineering units as count*Gain + Offset
+type Scale struct {
+	Gain   float64
+	Offset float64
+}
+
+// Apply converts a raw signed count to engineering units
+func (s Scale) Apply(count int16) float64 {
+	return float64(count)*s.Gain + s.Offset
+}
+
+// ReadScaled reads one holding register as a signed 16-bit count and returns
+// value*gain + offset
+func (c *Client) ReadScaled(slaveID byte, address uint16, gain, offset float64) (float64, error) {
+	registers, err := c.ReadHoldingRegisters(slaveID, address, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return Scale{Gain: gain, Offset: offset}.Apply(int16(registers[0])), nil
+}
+
+// ReadScaledBlock reads one holding register per scale, starting at address,
+// in a single request and applies each channel's scale to its register
+func (c *Client) ReadScaledBlock(slaveID byte, address uint16, scales []Scale) ([]float64, error) {
+	if len(scales) == 0 || len(scales) > 125 {
+		return nil, fmt.Errorf("invalid channel count: %d (must be 1-125)", len(scales))
+	}
+
+	registers, err := c.ReadHoldingRegisters(slaveID, address, uint16(len(scales)))
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]float64, len(scales))
+	for i, scale := range scales {
+		values[i] = scale.Apply(int16(registers[i]))
+	}
+
+	return values, nil
+}
@@ -0,0 +1,59 @@
+package modbus
+
+import "testing"
+
+// TestScaleApply tests converting signed counts to engineering units
+func TestScaleApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		scale    Scale
+		count    int16
+		expected float64
+	}{
+		{"identity", Scale{Gain: 1}, 123, 123},
+		{"tenths", Scale{Gain: 0.1}, 250, 25},
+		{"negative count", Scale{Gain: 0.5, Offset: 10}, -20, 0},
+		{"offset only", Scale{Offset: -40}, 1000, -40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if value := tt.scale.Apply(tt.count); value != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, value)
+			}
+		})
+	}
+}
+
+// TestReadScaled tests reading scaled analog inputs
+func TestReadScaled(t *testing.T) {
+	device := NewMemoryTransport()
+	client := NewClientWithTransport(device, ClientConfig{})
+
+	device.SetRegister(0, uint16(0xFFF6)) // -10
+	device.SetRegister(1, 400)
+	device.SetRegister(2, 1000)
+
+	value, err := client.ReadScaled(1, 0, 0.5, 20)
+	if err != nil {
+		t.Fatalf("ReadScaled failed: %v", err)
+	}
+	if value != 15 {
+		t.Errorf("Expected 15, got %v", value)
+	}
+
+	values, err := client.ReadScaledBlock(1, 0, []Scale{{Gain: 1}, {Gain: 0.1}, {Gain: 0.01, Offset: -5}})
+	if err != nil {
+		t.Fatalf("ReadScaledBlock failed: %v", err)
+	}
+	expected := []float64{-10, 40, 5}
+	for i := range expected {
+		if values[i] != expected[i] {
+			t.Errorf("Channel %d: expected %v, got %v", i, expected[i], values[i])
+		}
+	}
+
+	if _, err := client.ReadScaledBlock(1, 0, nil); err == nil {
+		t.Error("Expected error for empty scale list")
+	}
+}
@@ -0,0 +1,38 @@
+package modbus
+
+import (
+	"context"
+	"errors"
+)
+
+// ScanSlaves probes every unit ID from start to end inclusive and returns the
+// ones that answered, in ascending order. An ID counts as responsive when
+// probe returns nil or a Modbus exception, since either way a device replied;
+// timeouts and other failures are skipped. A nil probe uses Read Exception
+// Status (function code 0x07). Scanning stops early, returning the IDs found
+// so far, once ctx is done. The built-in probe also stops waiting for a reply
+// at the context deadline.
+func (c *Client) ScanSlaves(ctx context.Context, start, end byte, probe func(c *Client, id byte) error) []byte {
+	if probe == nil {
+		probe = func(c *Client, id byte) error {
+			deadline, _ := ctx.Deadline()
+			_, err := c.readExceptionStatus(id, requestOptions{deadline: deadline})
+			return err
+		}
+	}
+
+	var found []byte
+	for id := int(start); id <= int(end); id++ {
+		if ctx.Err() != nil {
+			break
+		}
+
+		err := probe(c, byte(id))
+		var modbusErr *ModbusError
+		if err == nil || errors.As(err, &modbusErr) {
+			found = append(found, byte(id))
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,66 @@
+package modbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestScanSlaves tests discovery of responsive unit IDs
+func TestScanSlaves(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		switch unitID {
+		case 2:
+			return nil // Default handling answers with an exception
+		case 5:
+			return []byte{FuncCodeReadExceptionStatus, 0x6D}
+		}
+		return []byte{} // Everyone else stays silent
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: 50 * time.Millisecond})
+
+	found := client.ScanSlaves(context.Background(), 1, 6, nil)
+	if len(found) != 2 || found[0] != 2 || found[1] != 5 {
+		t.Errorf("Expected [2 5], got %v", found)
+	}
+
+	status, err := client.ReadExceptionStatus(5)
+	if err != nil {
+		t.Fatalf("ReadExceptionStatus failed: %v", err)
+	}
+	if status != 0x6D {
+		t.Errorf("Expected status 0x6D, got 0x%02X", status)
+	}
+
+	// A custom probe decides what responsive means
+	probed := 0
+	found = client.ScanSlaves(context.Background(), 250, 255, func(c *Client, id byte) error {
+		probed++
+		return nil
+	})
+	if probed != 6 || len(found) != 6 || found[5] != 255 {
+		t.Errorf("Expected all of 250-255 found with 6 probes, got %v after %d probes", found, probed)
+	}
+}
+
+// TestScanSlavesCancel tests that scanning stops when the context ends
+func TestScanSlavesCancel(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		return []byte{} // Nobody answers
+	}
+	client := newTestClientWithConfig(t, server, ClientConfig{Timeout: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	begin := time.Now()
+	found := client.ScanSlaves(ctx, 1, 247, nil)
+	if elapsed := time.Since(begin); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected scan to stop near the context deadline, took %v", elapsed)
+	}
+	if len(found) != 0 {
+		t.Errorf("Expected no responsive IDs, got %v", found)
+	}
+}
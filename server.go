@@ -0,0 +1,197 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// Handler serves Modbus requests addressed to a single unit ID
+type Handler interface {
+	// ServeModbus handles a request PDU and returns the response PDU.
+	// Returning a *ModbusError sends the corresponding exception response,
+	// any other error is reported as a slave device failure. A nil response
+	// with a nil error sends nothing.
+	ServeModbus(unitID byte, pdu []byte) ([]byte, error)
+}
+
+// HandlerFunc adapts an ordinary function to the Handler interface
+type HandlerFunc func(unitID byte, pdu []byte) ([]byte, error)
+
+// ServeModbus calls f(unitID, pdu)
+func (f HandlerFunc) ServeModbus(unitID byte, pdu []byte) ([]byte, error) {
+	return f(unitID, pdu)
+}
+
+// ErrServerClosed is returned by Serve after Close has been called
+var ErrServerClosed = errors.New("modbus: server closed")
+
+// Server is a Modbus TCP server that routes requests to handlers by the
+// unit ID in the MBAP header, so one listener can front several logical slaves
+type Server struct {
+	mutex     sync.RWMutex
+	handlers  map[byte]Handler
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer creates a server with no registered units
+func NewServer() *Server {
+	return &Server{
+		handlers:  make(map[byte]Handler),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// Handle registers the handler for a unit ID, replacing any existing one.
+// Requests to unit IDs without a handler are answered with exception 0x0B
+// (gateway target device failed to respond).
+func (s *Server) Handle(unitID byte, h Handler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers[unitID] = h
+}
+
+// ListenAndServe listens on the TCP address and serves requests until Close is called
+func (s *Server) ListenAndServe(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on the listener and serves each in its own
+// goroutine. It always returns a non-nil error; after Close it is ErrServerClosed.
+func (s *Server) Serve(listener net.Listener) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+	s.listeners[listener] = struct{}{}
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.listeners, listener)
+		s.mutex.Unlock()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mutex.RLock()
+			closed := s.closed
+			s.mutex.RUnlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mutex.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops all listeners, closes open connections and waits for their
+// goroutines to finish
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+	for listener := range s.listeners {
+		listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// serveConn answers requests on a single connection until it fails or is closed
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		s.wg.Done()
+	}()
+
+	header := make([]byte, 7)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+
+		length := binary.BigEndian.Uint16(header[4:6])
+		if length < 2 || length > 254 {
+			return // Malformed frame, the stream can't be resynchronized
+		}
+		pdu := make([]byte, length-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+
+		response := s.dispatch(header[6], pdu)
+		if response == nil {
+			continue
+		}
+
+		frame := make([]byte, 7, 7+len(response))
+		copy(frame, header[0:4]) // Echo transaction and protocol IDs
+		binary.BigEndian.PutUint16(frame[4:6], uint16(len(response)+1))
+		frame[6] = header[6]
+		if _, err := conn.Write(append(frame, response...)); err != nil {
+			return
+		}
+	}
+}
+
+// dispatch routes a request to the handler registered for its unit ID and
+// converts handler errors to exception responses
+func (s *Server) dispatch(unitID byte, pdu []byte) []byte {
+	s.mutex.RLock()
+	handler := s.handlers[unitID]
+	s.mutex.RUnlock()
+
+	if handler == nil {
+		return exceptionPDU(pdu[0], ExceptionGatewayTargetFailed)
+	}
+
+	response, err := handler.ServeModbus(unitID, pdu)
+	if err != nil {
+		var modbusErr *ModbusError
+		if errors.As(err, &modbusErr) {
+			return exceptionPDU(pdu[0], modbusErr.ExceptionCode)
+		}
+		return exceptionPDU(pdu[0], ExceptionSlaveDeviceFailure)
+	}
+
+	return response
+}
+
+// exceptionPDU builds an exception response for the given function code
+func exceptionPDU(funcCode, exceptionCode byte) []byte {
+	return []byte{funcCode | 0x80, exceptionCode}
+}
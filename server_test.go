@@ -0,0 +1,104 @@
+package modbus
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// startTestServer serves s on a loopback port and returns a client connected to it
+func startTestServer(t *testing.T, s *Server) *Client {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go s.Serve(listener)
+	t.Cleanup(func() { s.Close() })
+
+	client, err := NewClient(ClientConfig{
+		Address: listener.Addr().String(),
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to server: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// registerHandler returns a handler answering holding register reads with a fixed value
+func registerHandler(value uint16) Handler {
+	return HandlerFunc(func(unitID byte, pdu []byte) ([]byte, error) {
+		if pdu[0] != FuncCodeReadHoldingRegisters {
+			return nil, &ModbusError{FunctionCode: pdu[0], ExceptionCode: ExceptionIllegalFunction}
+		}
+		return []byte{pdu[0], 2, byte(value >> 8), byte(value)}, nil
+	})
+}
+
+// TestServerUnitRouting tests routing requests to per-unit handlers
+func TestServerUnitRouting(t *testing.T) {
+	server := NewServer()
+	server.Handle(1, registerHandler(111))
+	server.Handle(2, registerHandler(222))
+	client := startTestServer(t, server)
+
+	for unitID, expected := range map[byte]uint16{1: 111, 2: 222} {
+		registers, err := client.ReadHoldingRegisters(unitID, 0, 1)
+		if err != nil {
+			t.Fatalf("Read from unit %d failed: %v", unitID, err)
+		}
+		if registers[0] != expected {
+			t.Errorf("Unit %d: expected %d, got %d", unitID, expected, registers[0])
+		}
+	}
+
+	_, err := client.ReadHoldingRegisters(3, 0, 1)
+	modbusErr, ok := err.(*ModbusError)
+	if !ok {
+		t.Fatalf("Expected *ModbusError for unregistered unit, got %v", err)
+	}
+	if modbusErr.ExceptionCode != ExceptionGatewayTargetFailed {
+		t.Errorf("Expected exception 0x0B, got 0x%02X", modbusErr.ExceptionCode)
+	}
+}
+
+// TestServerHandlerErrors tests conversion of handler errors to exceptions
+func TestServerHandlerErrors(t *testing.T) {
+	server := NewServer()
+	server.Handle(1, registerHandler(0))
+	client := startTestServer(t, server)
+
+	_, err := client.ReadCoils(1, 0, 1)
+	modbusErr, ok := err.(*ModbusError)
+	if !ok || modbusErr.ExceptionCode != ExceptionIllegalFunction {
+		t.Errorf("Expected illegal function exception, got %v", err)
+	}
+}
+
+// TestServerClose tests that Serve returns after Close
+func TestServerClose(t *testing.T) {
+	server := NewServer()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(listener) }()
+
+	time.Sleep(10 * time.Millisecond)
+	server.Close()
+
+	select {
+	case err := <-done:
+		if err != ErrServerClosed {
+			t.Errorf("Expected ErrServerClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Serve did not return after Close")
+	}
+}
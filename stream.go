@@ -0,0 +1,139 @@
+package modbus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// RegisterReadWriter reads and writes a fixed window of holding registers
+// as a byte stream, two bytes per register with the high byte first. Reads
+// and writes share one position, which Seek moves. Requests are split to
+// the per-request register limits, and writes that start or end half way
+// through a register read it first so its other byte is preserved.
+type RegisterReadWriter struct {
+	client   *Client
+	slaveID  byte
+	address  uint16
+	quantity uint16
+	offset   int64 // position in bytes from the start of the window
+}
+
+// RegisterReadWriter returns a byte stream over quantity holding registers
+// starting at address, positioned at the start of the window
+func (c *Client) RegisterReadWriter(slaveID byte, address, quantity uint16) *RegisterReadWriter {
+	return &RegisterReadWriter{client: c, slaveID: slaveID, address: address, quantity: quantity}
+}
+
+// size returns the window length in bytes
+func (s *RegisterReadWriter) size() int64 {
+	return 2 * int64(s.quantity)
+}
+
+// Read reads up to len(p) bytes from the current position, returning io.EOF
+// at the end of the window
+func (s *RegisterReadWriter) Read(p []byte) (int, error) {
+	if s.offset >= s.size() {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	n := int64(len(p))
+	if remaining := s.size() - s.offset; n > remaining {
+		n = remaining
+	}
+
+	first, last := s.offset/2, (s.offset+n-1)/2
+	registers, err := s.client.ReadRegistersChunked(s.slaveID, s.address+uint16(first), uint16(last-first+1))
+	if err != nil {
+		return 0, err
+	}
+
+	copy(p[:n], registersToBytes(registers, false)[s.offset%2:])
+	s.offset += n
+	return int(n), nil
+}
+
+// Write writes p at the current position. Bytes that don't fit in the window
+// are not written and the write fails with io.ErrShortWrite.
+func (s *RegisterReadWriter) Write(p []byte) (int, error) {
+	n := int64(len(p))
+	if remaining := s.size() - s.offset; n > remaining {
+		n = remaining
+	}
+	if n <= 0 {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.ErrShortWrite
+	}
+
+	first, last := s.offset/2, (s.offset+n-1)/2
+	data := make([]byte, 2*(last-first+1))
+
+	// Keep the other byte of a partially overwritten first or last register
+	if s.offset%2 == 1 {
+		if err := s.readRegisterBytes(first, data[0:2]); err != nil {
+			return 0, err
+		}
+	}
+	if (s.offset+n)%2 == 1 {
+		if err := s.readRegisterBytes(last, data[len(data)-2:]); err != nil {
+			return 0, err
+		}
+	}
+	copy(data[s.offset%2:], p[:n])
+
+	err := s.client.WriteRegistersChunked(s.slaveID, s.address+uint16(first), bytesToRegisters(data, false))
+	if err != nil {
+		// Report the bytes of p that landed in fully written registers
+		var partial *PartialWriteError
+		if !errors.As(err, &partial) {
+			return 0, err
+		}
+		written := 2*int64(partial.Written) - s.offset%2
+		if written < 0 {
+			written = 0
+		}
+		s.offset += written
+		return int(written), err
+	}
+
+	s.offset += n
+	if n < int64(len(p)) {
+		return int(n), io.ErrShortWrite
+	}
+	return int(n), nil
+}
+
+// readRegisterBytes reads the register at index within the window into dst
+func (s *RegisterReadWriter) readRegisterBytes(index int64, dst []byte) error {
+	registers, err := s.client.ReadHoldingRegisters(s.slaveID, s.address+uint16(index), 1)
+	if err != nil {
+		return err
+	}
+	copy(dst, registersToBytes(registers, false))
+	return nil
+}
+
+// Seek sets the position for the next Read or Write, as io.Seeker. Positions
+// past the end of the window are allowed; reads there return io.EOF.
+func (s *RegisterReadWriter) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.offset
+	case io.SeekEnd:
+		offset += s.size()
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position: %d", offset)
+	}
+
+	s.offset = offset
+	return offset, nil
+}
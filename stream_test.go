@@ -0,0 +1,77 @@
+package modbus
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// TestRegisterReadWriter tests copying a blob through a register window
+func TestRegisterReadWriter(t *testing.T) {
+	device := NewMemoryTransport()
+	client := NewClientWithTransport(device, ClientConfig{})
+
+	// 600 bytes needs several requests each way
+	blob := make([]byte, 600)
+	for i := range blob {
+		blob[i] = byte(i * 7)
+	}
+
+	stream := client.RegisterReadWriter(1, 1000, 300)
+	n, err := io.Copy(stream, bytes.NewReader(blob))
+	if err != nil || n != 600 {
+		t.Fatalf("Expected to copy 600 bytes, got %d (err %v)", n, err)
+	}
+	if device.Register(1000) != uint16(blob[0])<<8|uint16(blob[1]) {
+		t.Errorf("Expected high byte first, got register 0x%04X", device.Register(1000))
+	}
+
+	if _, err := stream.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	read, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if !bytes.Equal(read, blob) {
+		t.Error("Expected to read back the written blob")
+	}
+
+	// The window is full
+	if _, err := stream.Write([]byte{1}); !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("Expected io.ErrShortWrite past the window, got %v", err)
+	}
+}
+
+// TestRegisterReadWriterOddOffsets tests writes that split registers
+func TestRegisterReadWriterOddOffsets(t *testing.T) {
+	device := NewMemoryTransport()
+	client := NewClientWithTransport(device, ClientConfig{})
+	device.SetRegister(0, 0xAABB)
+	device.SetRegister(1, 0xCCDD)
+	device.SetRegister(2, 0xEEFF)
+
+	stream := client.RegisterReadWriter(1, 0, 3)
+	stream.Seek(1, io.SeekStart)
+	if n, err := stream.Write([]byte{0x11, 0x22, 0x33}); err != nil || n != 3 {
+		t.Fatalf("Expected to write 3 bytes, got %d (err %v)", n, err)
+	}
+
+	expected := []uint16{0xAA11, 0x2233, 0xEEFF}
+	for i, value := range expected {
+		if device.Register(uint16(i)) != value {
+			t.Errorf("Register %d: expected 0x%04X, got 0x%04X", i, value, device.Register(uint16(i)))
+		}
+	}
+
+	stream.Seek(-3, io.SeekEnd)
+	buf := make([]byte, 8)
+	n, err := stream.Read(buf)
+	if err != nil || !bytes.Equal(buf[:n], []byte{0x33, 0xEE, 0xFF}) {
+		t.Errorf("Expected [33 EE FF], got [% X] (err %v)", buf[:n], err)
+	}
+	if _, err := stream.Read(buf); err != io.EOF {
+		t.Errorf("Expected io.EOF at the end of the window, got %v", err)
+	}
+}
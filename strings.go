@@ -0,0 +1,74 @@
+package modbus
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReadString reads registerCount holding registers holding ASCII text packed
+// two characters per register and returns it with trailing NULs trimmed.
+// Only the byte half of the order matters, as every register holds its own
+// pair of characters: ABCD and CDAB (ByteOrderBig and ByteOrderLittle) take
+// the high byte of each register first, BADC and DCBA the low byte. NULs
+// inside the text are preserved.
+func (c *Client) ReadString(slaveID byte, address, registerCount uint16, byteOrder ByteOrder) (string, error) {
+	_, swapBytes, err := wordLayout(byteOrder)
+	if err != nil {
+		return "", err
+	}
+
+	registers, err := c.ReadHoldingRegisters(slaveID, address, registerCount)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(registersToBytes(registers, swapBytes)), "\x00"), nil
+}
+
+// WriteString packs value two characters per register and writes it across
+// registerCount holding registers, padding the remainder with NULs. The byte
+// order selects which half of each register holds the first character, as
+// for ReadString.
+func (c *Client) WriteString(slaveID byte, address, registerCount uint16, value string, byteOrder ByteOrder) error {
+	_, swapBytes, err := wordLayout(byteOrder)
+	if err != nil {
+		return err
+	}
+	if len(value) > 2*int(registerCount) {
+		return fmt.Errorf("string too long: %d bytes does not fit in %d registers", len(value), registerCount)
+	}
+
+	data := make([]byte, 2*int(registerCount))
+	copy(data, value)
+
+	return c.WriteMultipleRegisters(slaveID, address, bytesToRegisters(data, swapBytes))
+}
+
+// registersToBytes unpacks registers into bytes, two per register, high
+// byte first unless swapBytes is set
+func registersToBytes(registers []uint16, swapBytes bool) []byte {
+	data := make([]byte, 2*len(registers))
+	for i, reg := range registers {
+		high, low := byte(reg>>8), byte(reg)
+		if swapBytes {
+			high, low = low, high
+		}
+		data[2*i] = high
+		data[2*i+1] = low
+	}
+	return data
+}
+
+// bytesToRegisters packs an even number of bytes into registers, the first
+// of each pair into the high byte unless swapBytes is set
+func bytesToRegisters(data []byte, swapBytes bool) []uint16 {
+	registers := make([]uint16, len(data)/2)
+	for i := range registers {
+		first, second := data[2*i], data[2*i+1]
+		if swapBytes {
+			first, second = second, first
+		}
+		registers[i] = uint16(first)<<8 | uint16(second)
+	}
+	return registers
+}
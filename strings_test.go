@@ -0,0 +1,96 @@
+package modbus
+
+import "testing"
+
+// TestStringRoundTrip tests packing and unpacking strings in every byte order
+func TestStringRoundTrip(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	tests := []struct {
+		name      string
+		value     string
+		count     uint16
+		byteOrder ByteOrder
+		first     uint16 // Expected raw value of the first register
+		expected  string
+	}{
+		{"even big", "ABCD", 2, ByteOrderBig, 0x4142, "ABCD"},
+		{"odd big", "SN123", 4, ByteOrderBig, 0x534E, "SN123"},
+		{"odd little", "SN123", 4, ByteOrderLittle, 0x534E, "SN123"},
+		{"ABCD", "TAG1", 2, ByteOrderABCD, 0x5441, "TAG1"},
+		{"CDAB", "TAG1", 2, ByteOrderCDAB, 0x5441, "TAG1"},
+		{"BADC", "TAG1", 2, ByteOrderBADC, 0x4154, "TAG1"},
+		{"DCBA", "SN123", 4, ByteOrderDCBA, 0x4E53, "SN123"},
+		{"default", "TAG1", 2, "", 0x5441, "TAG1"},
+		{"embedded NUL", "A\x00B", 3, ByteOrderBig, 0x4100, "A\x00B"},
+		{"empty", "", 2, ByteOrderBig, 0x0000, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := client.WriteString(1, 100, tt.count, tt.value, tt.byteOrder); err != nil {
+				t.Fatalf("WriteString failed: %v", err)
+			}
+
+			server.mutex.Lock()
+			first := server.registers[100]
+			server.mutex.Unlock()
+			if first != tt.first {
+				t.Errorf("Expected first register 0x%04X, got 0x%04X", tt.first, first)
+			}
+
+			value, err := client.ReadString(1, 100, tt.count, tt.byteOrder)
+			if err != nil {
+				t.Fatalf("ReadString failed: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, value)
+			}
+		})
+	}
+}
+
+// TestStringDefaultByteOrder tests that a word-swapped package default is
+// accepted and keeps the high byte first
+func TestStringDefaultByteOrder(t *testing.T) {
+	defer SetDefaultByteOrder(DefaultByteOrder())
+	SetDefaultByteOrder(ByteOrderCDAB)
+
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	if err := client.WriteString(1, 0, 2, "AB", DefaultByteOrder()); err != nil {
+		t.Fatalf("WriteString failed: %v", err)
+	}
+	server.mutex.Lock()
+	first := server.registers[0]
+	server.mutex.Unlock()
+	if first != 0x4142 {
+		t.Errorf("Expected first register 0x4142, got 0x%04X", first)
+	}
+
+	value, err := client.ReadString(1, 0, 2, "")
+	if err != nil {
+		t.Fatalf("ReadString failed: %v", err)
+	}
+	if value != "AB" {
+		t.Errorf("Expected %q, got %q", "AB", value)
+	}
+}
+
+// TestStringErrors tests rejection of oversized strings and bad byte orders
+func TestStringErrors(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	if err := client.WriteString(1, 0, 2, "TOOLONG", ByteOrderBig); err == nil {
+		t.Error("Expected error for string longer than the register range")
+	}
+	if err := client.WriteString(1, 0, 2, "AB", "middle"); err == nil {
+		t.Error("Expected error for invalid byte order on write")
+	}
+	if _, err := client.ReadString(1, 0, 2, "middle"); err == nil {
+		t.Error("Expected error for invalid byte order on read")
+	}
+}
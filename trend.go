@@ -0,0 +1,147 @@
+package modbus
+
+import (
+	"sync"
+	"time"
+)
+
+// TrendSample is a single input register reading captured by a TrendBuffer
+type TrendSample struct {
+	Time  time.Time // When the sample was taken
+	Value uint16    // Register value
+}
+
+// TrendBuffer samples an input register on a fixed interval into a circular
+// buffer, keeping the most recent samples for local trending
+type TrendBuffer struct {
+	client   *Client
+	slaveID  byte
+	address  uint16
+	interval time.Duration
+
+	mutex   sync.Mutex
+	samples []TrendSample
+	next    int // Index the next sample is written to once the buffer is full
+	lastErr error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTrendBuffer creates a trend buffer holding up to capacity samples of the
+// input register at address. Sampling begins when Start is called.
+func NewTrendBuffer(client *Client, slaveID byte, address uint16, capacity int, interval time.Duration) *TrendBuffer {
+	if capacity <= 0 {
+		capacity = 100
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	return &TrendBuffer{
+		client:   client,
+		slaveID:  slaveID,
+		address:  address,
+		interval: interval,
+		samples:  make([]TrendSample, 0, capacity),
+	}
+}
+
+// Start begins sampling in a background goroutine. Calling Start on a
+// buffer that is already running has no effect.
+func (b *TrendBuffer) Start() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.stop != nil {
+		return
+	}
+	b.stop = make(chan struct{})
+	b.done = make(chan struct{})
+	go b.run(b.stop, b.done)
+}
+
+// Stop halts sampling and waits for the background goroutine to exit.
+// Collected samples remain available.
+func (b *TrendBuffer) Stop() {
+	b.mutex.Lock()
+	stop, done := b.stop, b.done
+	b.stop, b.done = nil, nil
+	b.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// run samples on every tick until stop is closed
+func (b *TrendBuffer) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			b.sample()
+		}
+	}
+}
+
+// sample reads the register once and records the value. Failed reads are
+// not recorded but are reported by Err.
+func (b *TrendBuffer) sample() error {
+	values, err := b.client.ReadInputRegisters(b.slaveID, b.address, 1)
+	now := time.Now()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.lastErr = err
+	if err != nil {
+		return err
+	}
+
+	sample := TrendSample{Time: now, Value: values[0]}
+	if len(b.samples) < cap(b.samples) {
+		b.samples = append(b.samples, sample)
+		return nil
+	}
+	b.samples[b.next] = sample
+	b.next = (b.next + 1) % len(b.samples)
+
+	return nil
+}
+
+// Samples returns the buffered samples with their timestamps, oldest first
+func (b *TrendBuffer) Samples() []TrendSample {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	samples := make([]TrendSample, 0, len(b.samples))
+	samples = append(samples, b.samples[b.next:]...)
+	samples = append(samples, b.samples[:b.next]...)
+	return samples
+}
+
+// Snapshot returns the buffered register values, oldest first
+func (b *TrendBuffer) Snapshot() []uint16 {
+	samples := b.Samples()
+	values := make([]uint16, len(samples))
+	for i, sample := range samples {
+		values[i] = sample.Value
+	}
+	return values
+}
+
+// Err returns the error from the most recent sampling attempt, if any
+func (b *TrendBuffer) Err() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.lastErr
+}
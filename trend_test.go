@@ -0,0 +1,73 @@
+package modbus
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTrendBufferOrdering tests that samples are kept oldest-to-newest and capped at capacity
+func TestTrendBufferOrdering(t *testing.T) {
+	server := NewMockServer()
+	client := newTestClient(t, server)
+
+	buffer := NewTrendBuffer(client, 1, 5, 3, time.Hour)
+
+	for value := uint16(1); value <= 5; value++ {
+		server.mutex.Lock()
+		server.registers[5] = value
+		server.mutex.Unlock()
+
+		if err := buffer.sample(); err != nil {
+			t.Fatalf("sample %d failed: %v", value, err)
+		}
+
+		if value == 2 {
+			snapshot := buffer.Snapshot()
+			if len(snapshot) != 2 || snapshot[0] != 1 || snapshot[1] != 2 {
+				t.Errorf("Expected partial snapshot [1 2], got %v", snapshot)
+			}
+		}
+	}
+
+	snapshot := buffer.Snapshot()
+	expected := []uint16{3, 4, 5}
+	if len(snapshot) != len(expected) {
+		t.Fatalf("Expected %d samples, got %d", len(expected), len(snapshot))
+	}
+	for i := range expected {
+		if snapshot[i] != expected[i] {
+			t.Errorf("Sample %d: expected %d, got %d", i, expected[i], snapshot[i])
+		}
+	}
+
+	samples := buffer.Samples()
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Time.Before(samples[i-1].Time) {
+			t.Errorf("Sample %d timestamp %v is before sample %d timestamp %v",
+				i, samples[i].Time, i-1, samples[i-1].Time)
+		}
+	}
+}
+
+// TestTrendBufferStartStop tests background sampling on the ticker
+func TestTrendBufferStartStop(t *testing.T) {
+	client := newTestClient(t, NewMockServer())
+
+	buffer := NewTrendBuffer(client, 1, 0, 10, 5*time.Millisecond)
+	buffer.Start()
+	time.Sleep(50 * time.Millisecond)
+	buffer.Stop()
+
+	count := len(buffer.Snapshot())
+	if count == 0 {
+		t.Fatal("Expected samples to be collected while running")
+	}
+	if err := buffer.Err(); err != nil {
+		t.Errorf("Unexpected sampling error: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if len(buffer.Snapshot()) != count {
+		t.Error("Expected no samples to be collected after Stop")
+	}
+}
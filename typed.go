@@ -0,0 +1,117 @@
+package modbus
+
+import (
+	"fmt"
+	"math"
+)
+
+// Read reads quantity bits or registers from any of the four tables, as
+// []bool for coils and discrete inputs or []uint16 for registers
+func (c *Client) Read(slaveID byte, table Table, address, quantity uint16) (interface{}, error) {
+	switch table {
+	case TableCoils:
+		return c.ReadCoils(slaveID, address, quantity)
+	case TableDiscreteInputs:
+		return c.ReadDiscreteInputs(slaveID, address, quantity)
+	case TableInputRegisters:
+		return c.ReadInputRegisters(slaveID, address, quantity)
+	case TableHoldingRegisters:
+		return c.ReadHoldingRegisters(slaveID, address, quantity)
+	default:
+		return nil, fmt.Errorf("cannot read from %s", table)
+	}
+}
+
+// PointValue is a value type ReadAs can decode: bool from coils and
+// discrete inputs, or a 16- or 32-bit number from registers
+type PointValue interface {
+	bool | uint16 | int16 | uint32 | int32 | float32
+}
+
+// ReadAs reads count consecutive values of type T from table, starting at
+// address. 32-bit types take two registers each, in byteOrder word order;
+// byteOrder is ignored for bits and 16-bit types. Reads larger than one
+// request allows are split as the Chunked functions do, never splitting a
+// 32-bit value.
+//
+//	temperatures, err := modbus.ReadAs[float32](client, 1, modbus.TableInputRegisters, 0, 4, modbus.ByteOrderBig)
+func ReadAs[T PointValue](c *Client, slaveID byte, table Table, address uint16, count int, byteOrder ByteOrder) ([]T, error) {
+	var zero T
+	bitTable := table == TableCoils || table == TableDiscreteInputs
+	if _, isBool := interface{}(zero).(bool); isBool != bitTable {
+		return nil, fmt.Errorf("cannot read %T values from %s", zero, table)
+	}
+
+	width := 1
+	switch interface{}(zero).(type) {
+	case uint32, int32, float32:
+		width = 2
+	}
+	if count <= 0 || count > 0xFFFF/width {
+		return nil, fmt.Errorf("invalid count: %d (must be 1-%d)", count, 0xFFFF/width)
+	}
+	values := make([]T, count)
+
+	if bitTable {
+		if err := checkAddressRange(address, count); err != nil {
+			return nil, err
+		}
+		funcCode := byte(FuncCodeReadCoils)
+		if table == TableDiscreteInputs {
+			funcCode = FuncCodeReadDiscreteInputs
+		}
+		bits, err := c.readBitsChunked(slaveID, funcCode, address, uint16(count))
+		if err != nil {
+			return nil, err
+		}
+		for i, bit := range bits {
+			*interface{}(&values[i]).(*bool) = bit
+		}
+		return values, nil
+	}
+
+	var funcCode byte
+	switch table {
+	case TableInputRegisters:
+		funcCode = FuncCodeReadInputRegisters
+	case TableHoldingRegisters:
+		funcCode = FuncCodeReadHoldingRegisters
+	default:
+		return nil, fmt.Errorf("cannot read from %s", table)
+	}
+
+	if err := checkAddressRange(address, width*count); err != nil {
+		return nil, err
+	}
+	registers, err := c.readRegistersChunked(slaveID, funcCode, address, uint16(width*count), width)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range values {
+		if width == 1 {
+			switch value := interface{}(&values[i]).(type) {
+			case *uint16:
+				*value = registers[i]
+			case *int16:
+				*value = int16(registers[i])
+			}
+			continue
+		}
+
+		bits, err := registersToUint32(registers[2*i:2*i+2], byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		switch value := interface{}(&values[i]).(type) {
+		case *uint32:
+			*value = bits
+		case *int32:
+			*value = int32(bits)
+		case *float32:
+			*value = math.Float32frombits(bits)
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,91 @@
+package modbus
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRead tests reading any table by its Table value
+func TestRead(t *testing.T) {
+	device := NewMemoryTransport()
+	device.SetRegister(5, 1234)
+	device.SetCoil(5, true)
+	client := NewClientWithTransport(device, ClientConfig{})
+	defer client.Close()
+
+	for _, table := range []Table{TableCoils, TableDiscreteInputs} {
+		values, err := client.Read(1, table, 5, 2)
+		if err != nil {
+			t.Fatalf("Read(%s) failed: %v", table, err)
+		}
+		bits, ok := values.([]bool)
+		if !ok || len(bits) != 2 || !bits[0] || bits[1] {
+			t.Errorf("%s: expected [true false], got %v", table, values)
+		}
+	}
+	for _, table := range []Table{TableInputRegisters, TableHoldingRegisters} {
+		values, err := client.Read(1, table, 5, 2)
+		if err != nil {
+			t.Fatalf("Read(%s) failed: %v", table, err)
+		}
+		registers, ok := values.([]uint16)
+		if !ok || len(registers) != 2 || registers[0] != 1234 || registers[1] != 0 {
+			t.Errorf("%s: expected [1234 0], got %v", table, values)
+		}
+	}
+
+	if _, err := client.Read(1, Table(2), 0, 1); err == nil {
+		t.Error("Expected error for unknown table")
+	}
+}
+
+// TestReadAs tests typed reads across tables and value types
+func TestReadAs(t *testing.T) {
+	device := NewMemoryTransport()
+	bits := math.Float32bits(-2.5)
+	device.SetRegister(0, uint16(bits>>16))
+	device.SetRegister(1, uint16(bits))
+	device.SetRegister(2, 0xFFFF)
+	device.SetRegister(3, 0xFFFE)
+	device.SetCoil(1, true)
+	client := NewClientWithTransport(device, ClientConfig{})
+	defer client.Close()
+
+	floats, err := ReadAs[float32](client, 1, TableInputRegisters, 0, 1, ByteOrderBig)
+	if err != nil || len(floats) != 1 || floats[0] != -2.5 {
+		t.Errorf("Expected [-2.5], got %v (err %v)", floats, err)
+	}
+
+	signed, err := ReadAs[int32](client, 1, TableHoldingRegisters, 2, 1, ByteOrderBig)
+	if err != nil || len(signed) != 1 || signed[0] != -2 {
+		t.Errorf("Expected [-2], got %v (err %v)", signed, err)
+	}
+
+	words, err := ReadAs[int16](client, 1, TableHoldingRegisters, 2, 2, ByteOrderBig)
+	if err != nil || len(words) != 2 || words[0] != -1 || words[1] != -2 {
+		t.Errorf("Expected [-1 -2], got %v (err %v)", words, err)
+	}
+
+	coils, err := ReadAs[bool](client, 1, TableCoils, 0, 3, ByteOrderBig)
+	if err != nil || len(coils) != 3 || coils[0] || !coils[1] || coils[2] {
+		t.Errorf("Expected [false true false], got %v (err %v)", coils, err)
+	}
+
+	large, err := ReadAs[uint32](client, 1, TableHoldingRegisters, 0, 100, ByteOrderBig) // 200 registers
+	if err != nil || len(large) != 100 || large[0] != bits {
+		t.Errorf("Expected 100 values starting with 0x%08X, got %d values (err %v)", bits, len(large), err)
+	}
+
+	if _, err := ReadAs[bool](client, 1, TableHoldingRegisters, 0, 1, ByteOrderBig); err == nil {
+		t.Error("Expected error reading bools from registers")
+	}
+	if _, err := ReadAs[uint16](client, 1, TableCoils, 0, 1, ByteOrderBig); err == nil {
+		t.Error("Expected error reading registers from coils")
+	}
+	if _, err := ReadAs[float32](client, 1, TableHoldingRegisters, 65535, 1, ByteOrderBig); err == nil {
+		t.Error("Expected error for value past the end of the address space")
+	}
+	if _, err := ReadAs[uint16](client, 1, TableHoldingRegisters, 0, 0, ByteOrderBig); err == nil {
+		t.Error("Expected error for zero count")
+	}
+}
@@ -0,0 +1,72 @@
+package modbus
+
+import (
+	"context"
+	"time"
+)
+
+// defaultWaitInterval is the polling interval WaitForRegister and
+// WaitForCoil use when none is given
+const defaultWaitInterval = 100 * time.Millisecond
+
+// WaitForRegister polls the holding register at address every interval
+// until predicate accepts its value or ctx ends, and returns the last value
+// read. Each read is also bounded by ctx's deadline. A failed read stops the
+// wait with its error; once ctx ends, ctx.Err() is returned.
+func (c *Client) WaitForRegister(ctx context.Context, slaveID byte, address uint16, predicate func(uint16) bool, interval time.Duration) (uint16, error) {
+	var value uint16
+	err := c.waitFor(ctx, interval, func(opts requestOptions) (bool, error) {
+		registers, err := c.readRegisters(slaveID, FuncCodeReadHoldingRegisters, address, 1, opts)
+		if err != nil {
+			return false, err
+		}
+		value = registers[0]
+		return predicate(value), nil
+	})
+	return value, err
+}
+
+// WaitForCoil polls the coil at address every interval until it reads as
+// want or ctx ends, as WaitForRegister does
+func (c *Client) WaitForCoil(ctx context.Context, slaveID byte, address uint16, want bool, interval time.Duration) error {
+	return c.waitFor(ctx, interval, func(opts requestOptions) (bool, error) {
+		response, err := c.readBitsResponse(slaveID, FuncCodeReadCoils, address, 1, opts)
+		if err != nil {
+			return false, err
+		}
+		return (response[2]&1 != 0) == want, nil
+	})
+}
+
+// waitFor runs check every interval until it reports done, fails or ctx
+// ends. check receives request options carrying ctx and its deadline.
+func (c *Client) waitFor(ctx context.Context, interval time.Duration, check func(opts requestOptions) (bool, error)) error {
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+	opts := requestOptions{ctx: ctx}
+	if deadline, ok := ctx.Deadline(); ok {
+		opts.deadline = deadline
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		done, err := check(opts)
+		if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || done {
+			return err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
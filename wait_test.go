@@ -0,0 +1,82 @@
+package modbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestWaitForRegister tests polling a status register until it is ready
+func TestWaitForRegister(t *testing.T) {
+	transport := NewMemoryTransport()
+	client := NewClientWithTransport(transport, ClientConfig{Timeout: time.Second})
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		transport.SetRegister(3, 1)
+		time.Sleep(30 * time.Millisecond)
+		transport.SetRegister(3, 2)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	value, err := client.WaitForRegister(ctx, 1, 3, func(v uint16) bool { return v == 2 }, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForRegister failed: %v", err)
+	}
+	if value != 2 {
+		t.Errorf("Expected 2, got %d", value)
+	}
+
+	// The condition never holds: the last value comes back with the context error
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	value, err = client.WaitForRegister(ctx, 1, 3, func(v uint16) bool { return v == 9 }, 5*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if value != 2 {
+		t.Errorf("Expected last value 2, got %d", value)
+	}
+}
+
+// TestWaitForRegisterError tests that a failed read ends the wait
+func TestWaitForRegisterError(t *testing.T) {
+	server := NewMockServer()
+	server.handler = func(unitID byte, pdu []byte) []byte {
+		return []byte{pdu[0] | 0x80, ExceptionIllegalDataAddress}
+	}
+	client := newTestClient(t, server)
+
+	_, err := client.WaitForRegister(context.Background(), 1, 0, func(uint16) bool { return true }, time.Millisecond)
+	if !errors.Is(err, ErrIllegalDataAddress) {
+		t.Errorf("Expected ErrIllegalDataAddress, got %v", err)
+	}
+}
+
+// TestWaitForCoil tests polling a coil until it reaches a state
+func TestWaitForCoil(t *testing.T) {
+	transport := NewMemoryTransport()
+	client := NewClientWithTransport(transport, ClientConfig{Timeout: time.Second})
+	transport.SetCoil(4, true)
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		transport.SetCoil(4, false)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.WaitForCoil(ctx, 1, 4, false, 5*time.Millisecond); err != nil {
+		t.Fatalf("WaitForCoil failed: %v", err)
+	}
+	if transport.Coil(4) {
+		t.Error("Expected the coil to be off once the wait returns")
+	}
+
+	cancel()
+	if err := client.WaitForCoil(ctx, 1, 4, true, 5*time.Millisecond); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}